@@ -0,0 +1,145 @@
+package dgogm
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/dgraph-io/dgraph/protos"
+)
+
+// This function adds count of the given edge to the query
+// Count is set to the int field tagged as count(edge) i.e. `dgraph:"count(likes_places)"`
+func (dq *DgQuery) CountEdge(edges ...string) *DgQuery {
+	for _, edge := range edges {
+		path, _, err := resolveEdgePath(dq.structType(), edge)
+		if err != nil {
+			dq.err = err
+			return dq
+		}
+		dq.counts = append(dq.counts, fmt.Sprintf("count(%s)", path))
+	}
+	return dq
+}
+
+// This function returns the number of nodes matching the query
+func (dq *DgQuery) Count() (int, error) {
+	return dq.CountContext(context.Background())
+}
+
+// This function returns the number of nodes matching the query
+// The query is cancelled when the given context is done
+func (dq *DgQuery) CountContext(ctx context.Context) (int, error) {
+	t := dq.structType()
+	if dq.err != nil {
+		return 0, dq.err
+	}
+	root, filter, err := dq.root(t)
+	if err != nil {
+		return 0, err
+	}
+	nodes, err := query(ctx, loggerOrDefault(dq.logger), dq.client, fmt.Sprintf(COUNT_NODES, t.Name(), root, filter))
+	if err != nil {
+		return 0, err
+	}
+	v, err := firstValue(nodes, "count")
+	if err != nil || v == nil {
+		return 0, err
+	}
+	count, ok := v.(int64)
+	if !ok {
+		return 0, fmt.Errorf("count returned %v", v)
+	}
+	return int(count), nil
+}
+
+// This function returns the minimum value of the given field among the matching nodes
+func (dq *DgQuery) Min(field string) (float64, error) {
+	return dq.aggregate(context.Background(), "min", field)
+}
+
+// This function returns the minimum value of the given field among the matching nodes
+// The query is cancelled when the given context is done
+func (dq *DgQuery) MinContext(ctx context.Context, field string) (float64, error) {
+	return dq.aggregate(ctx, "min", field)
+}
+
+// This function returns the maximum value of the given field among the matching nodes
+func (dq *DgQuery) Max(field string) (float64, error) {
+	return dq.aggregate(context.Background(), "max", field)
+}
+
+// This function returns the maximum value of the given field among the matching nodes
+// The query is cancelled when the given context is done
+func (dq *DgQuery) MaxContext(ctx context.Context, field string) (float64, error) {
+	return dq.aggregate(ctx, "max", field)
+}
+
+// This function returns the sum of the given field over the matching nodes
+func (dq *DgQuery) Sum(field string) (float64, error) {
+	return dq.aggregate(context.Background(), "sum", field)
+}
+
+// This function returns the sum of the given field over the matching nodes
+// The query is cancelled when the given context is done
+func (dq *DgQuery) SumContext(ctx context.Context, field string) (float64, error) {
+	return dq.aggregate(ctx, "sum", field)
+}
+
+// This function returns the average of the given field over the matching nodes
+func (dq *DgQuery) Avg(field string) (float64, error) {
+	return dq.aggregate(context.Background(), "avg", field)
+}
+
+// This function returns the average of the given field over the matching nodes
+// The query is cancelled when the given context is done
+func (dq *DgQuery) AvgContext(ctx context.Context, field string) (float64, error) {
+	return dq.aggregate(ctx, "avg", field)
+}
+
+// This function aggregates the given field of the matching nodes using value variable
+func (dq *DgQuery) aggregate(ctx context.Context, fn string, field string) (float64, error) {
+	t := dq.structType()
+	if dq.err != nil {
+		return 0, dq.err
+	}
+	f, ok := lookupField(t, field)
+	if !ok {
+		return 0, fmt.Errorf("%s does not have field %s", t.Name(), field)
+	}
+	if !isNumber(f.Type.Kind()) {
+		return 0, ErrUnsupportedType{Field: f.Name, Type: f.Type}
+	}
+	root, filter, err := dq.root(t)
+	if err != nil {
+		return 0, err
+	}
+	nodes, err := query(ctx, loggerOrDefault(dq.logger), dq.client, fmt.Sprintf(AGGREGATE_NODES, root, filter, getFieldName(f), t.Name(), fn))
+	if err != nil {
+		return 0, err
+	}
+	v, err := firstValue(nodes, "")
+	if err != nil || v == nil {
+		return 0, err
+	}
+	switch v.(type) {
+	case int64:
+		return float64(v.(int64)), nil
+	case float64:
+		return v.(float64), nil
+	}
+	return 0, fmt.Errorf("%s returned %v", fn, v)
+}
+
+// This function returns the value of the given property of the first result
+// If prop is empty, first property is returned
+func firstValue(nodes []*protos.Node, prop string) (interface{}, error) {
+	if len(nodes) == 0 || len(nodes[0].Children) == 0 {
+		return nil, nil
+	}
+	for _, p := range nodes[0].Children[0].Properties {
+		if prop == "" || p.Prop == prop {
+			return convert(p.Value)
+		}
+	}
+	return nil, nil
+}
@@ -16,48 +16,58 @@ type Dgraph struct {
 	Addresses []string
 	conns     []*grpc.ClientConn
 	client    *client.Dgraph
+	// Client dir is removed on Close only if it was generated by dgogm
+	clientDir string
+	tempDir   bool
+	// Client passed to ConnectWithClient is owned by the caller and is not closed
+	ownClient bool
+	// Logger for the operations done using this handle, global logger is used if it's nil
+	logger Logger
+	// Identity strategy for the structs written and queried using this handle, HashedXid is used if it's nil
+	ids IdentityStrategy
 }
 
 // This function connects to the underlying grpc server and creates dgraph connections
+// By default connections are insecure, use WithTLS and other options for configuring them
 // Dgraph 0.8.1 creates a client level cache, this function will create that file in
 // os.TempDir()/dgraph/<timestamp_of_connection>
-// If you require that file to be reused use ConnectWithClientDir
-func Connect(addresses []string) (*Dgraph, error) {
-	var err error
-	d := new(Dgraph)
-	d.Addresses = addresses
-	for _, address := range addresses {
-		c := context.Background()
-		c, _ = context.WithTimeout(c, time.Second*30)
-		conn, err := grpc.DialContext(c, address, grpc.WithInsecure(), grpc.WithBlock())
-		if err != nil {
-			return nil, err
-		}
-		d.conns = append(d.conns, conn)
+// If you require that file to be reused use WithClientDir
+func Connect(addresses []string, opts ...Option) (*Dgraph, error) {
+	return ConnectContext(context.Background(), addresses, opts...)
+}
+
+// This function connects to the underlying grpc server and creates dgraph connections, same as Connect
+// Dialing is aborted when the given context is done
+func ConnectContext(ctx context.Context, addresses []string, opts ...Option) (*Dgraph, error) {
+	o, tempDir := connectOptions(opts...)
+	conns, err := dial(ctx, addresses, o)
+	if err != nil {
+		return nil, err
+	}
+	d := &Dgraph{Addresses: addresses, conns: conns, clientDir: o.clientDir, tempDir: tempDir, ownClient: true}
+	d.client = client.NewDgraphClient(d.conns, o.clientOptions, o.clientDir)
+	return d, nil
+}
+
+// This function applies the given options over the default ones, it returns if the client dir is generated too
+func connectOptions(opts ...Option) (*options, bool) {
+	o := defaultOptions()
+	for _, opt := range opts {
+		opt(o)
+	}
+	if o.clientDir != "" {
+		return o, false
 	}
 	// This generates a unique folder structure for maintaining client cache
-	d.client = client.NewDgraphClient(d.conns, client.DefaultOptions, fmt.Sprintf("%s/dgraph/%s", os.TempDir(), time.Now().UTC().String()))
-	return d, err
+	o.clientDir = fmt.Sprintf("%s/dgraph/%s", os.TempDir(), time.Now().UTC().String())
+	return o, true
 }
 
 // This function connects to the underlying grpc server and creates dgraph connections
 // It will use the provided clientDir as the client dir in the connection
+// Deprecated: use Connect with WithClientDir
 func ConnectWithClientDir(addresses []string, clientDir string) (*Dgraph, error) {
-	var err error
-	d := new(Dgraph)
-	d.Addresses = addresses
-	for _, address := range addresses {
-		c := context.Background()
-		c, _ = context.WithTimeout(c, time.Second*30)
-		conn, err := grpc.DialContext(c, address, grpc.WithInsecure(), grpc.WithBlock())
-		if err != nil {
-			return nil, err
-		}
-		d.conns = append(d.conns, conn)
-	}
-	// This generates a unique folder structure for maintaining client cache
-	d.client = client.NewDgraphClient(d.conns, client.DefaultOptions, clientDir)
-	return d, err
+	return Connect(addresses, WithClientDir(clientDir))
 }
 
 // This function creates Dgraph object with provided client
@@ -65,3 +75,48 @@ func ConnectWithClientDir(addresses []string, clientDir string) (*Dgraph, error)
 func ConnectWithClient(c *client.Dgraph) (*Dgraph, error) {
 	return &Dgraph{client: c}, nil
 }
+
+// This function closes the underlying client and all the grpc connections
+// Client dir generated by Connect is removed, while the one given using WithClientDir is left intact
+// Client passed to ConnectWithClient is not closed, as it's owned by the caller
+func (d *Dgraph) Close() error {
+	var err error
+	if d.ownClient && d.client != nil {
+		err = d.client.Close()
+	}
+	for _, conn := range d.conns {
+		if cerr := conn.Close(); cerr != nil && err == nil {
+			err = cerr
+		}
+	}
+	d.conns = nil
+	if d.tempDir {
+		if rerr := os.RemoveAll(d.clientDir); rerr != nil && err == nil {
+			err = rerr
+		}
+		d.tempDir = false
+	}
+	return err
+}
+
+// This function dials all the given addresses, waiting at most dial timeout for each of them
+// If any of the address fails, already opened connections are closed
+func dial(ctx context.Context, addresses []string, o *options) ([]*grpc.ClientConn, error) {
+	var conns []*grpc.ClientConn
+	for _, address := range addresses {
+		c, cancel := ctx, context.CancelFunc(func() {})
+		if o.dialTimeout > 0 {
+			c, cancel = context.WithTimeout(ctx, o.dialTimeout)
+		}
+		conn, err := grpc.DialContext(c, address, o.grpcDialOptions()...)
+		cancel()
+		if err != nil {
+			for _, conn := range conns {
+				conn.Close()
+			}
+			return nil, err
+		}
+		conns = append(conns, conn)
+	}
+	return conns, nil
+}
@@ -1,5 +1,10 @@
 package dgogm
 
 const (
-	GET_NODE_FOR_ID = `{%s(func: uid(0x%x)){%s}}`
+	GET_NODE_FOR_ID      = `{%s(func: uid(0x%x)){%s}}`
+	GET_NODES_FOR_FILTER = `{%s(func: %s)%s{%s}}`
+	COUNT_NODES          = `{%s(func: %s)%s{count(_uid_)}}`
+	AGGREGATE_NODES      = `{var(func: %s)%s{v as %s} %s(){%s(val(v))}}`
+	ALTER_SCHEMA         = "mutation {\nschema {\n%s\n}\n}"
+	GET_SCHEMA           = `schema {}`
 )
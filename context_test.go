@@ -0,0 +1,44 @@
+package dgogm_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/akshaydeo/dgogm"
+	"github.com/dgraph-io/dgraph/client"
+	"github.com/dgraph-io/dgraph/protos"
+)
+
+func TestContextReachesRequests(t *testing.T) {
+	var got []context.Context
+	defer dgogm.StubRun(func(ctx context.Context, r *client.Req) (*protos.Response, error) {
+		got = append(got, ctx)
+		return nil, ctx.Err()
+	})()
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	values := map[string]interface{}{"name": "jarvis"}
+	calls := map[string]func() error{
+		"Add":           func() error { return dgogm.AddContext(ctx, nil, &Dog{Id: 1}) },
+		"Update":        func() error { return dgogm.UpdateContext(ctx, nil, &Dog{Id: 1}) },
+		"Patch":         func() error { return dgogm.PatchContext(ctx, nil, &Dog{Id: 1}, values) },
+		"Upsert":        func() error { return dgogm.UpsertContext(ctx, nil, &Owner1{Email: "akshay@example.com"}, "email") },
+		"Delete":        func() error { return dgogm.DeleteContext(ctx, nil, &Dog{Id: 1}) },
+		"DeleteCascade": func() error { return dgogm.DeleteCascadeContext(ctx, nil, &Dog{Id: 1}) },
+		"DeleteEdge":    func() error { return dgogm.DeleteEdgeContext(ctx, nil, &Dog{Id: 1}, "likes_places", nil) },
+		"Migrate":       func() error { return dgogm.MigrateContext(ctx, nil, &Dog{}) },
+		"Execute":       func() error { return dgogm.Find(nil, &Dog{Id: 1}).ExecuteContext(ctx) },
+		"Count":         func() error { _, err := dgogm.Find(nil, &Dog{Id: 1}).CountContext(ctx); return err },
+	}
+	for name, call := range calls {
+		got = nil
+		err := call()
+		if !errors.Is(err, context.Canceled) {
+			t.Fatal(name, err)
+		}
+		if len(got) == 0 || got[0] != ctx {
+			t.Fatal(name, "was not given the context")
+		}
+	}
+}
@@ -0,0 +1,237 @@
+package dgogm
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+
+	"github.com/dgraph-io/dgraph/client"
+)
+
+// This function deletes the node identified by GetUId(p) along with all of its outgoing predicates
+// Nodes connected to this node are left untouched, use DeleteCascade for removing them too
+func (d *Dgraph) Delete(p interface{}) error {
+	return d.DeleteContext(context.Background(), p)
+}
+
+// This function deletes the node identified by GetUId(p), same as Delete
+// The mutation is cancelled when the given context is done
+func (d *Dgraph) DeleteContext(ctx context.Context, p interface{}) error {
+	return deleteContext(ctx, d.log(), d.client, d.ids, p, false)
+}
+
+// This function deletes the node identified by GetUId(p) along with all of its outgoing predicates
+// Nodes connected to this node are left untouched, use DeleteCascade for removing them too
+func Delete(c *client.Dgraph, p interface{}) error {
+	return DeleteContext(context.Background(), c, p)
+}
+
+// This function deletes the node identified by GetUId(p), same as Delete
+// The mutation is cancelled when the given context is done
+func DeleteContext(ctx context.Context, c *client.Dgraph, p interface{}) error {
+	return deleteContext(ctx, getLogger(), c, nil, p, false)
+}
+
+// This function deletes the node identified by GetUId(p) and every node reachable through its
+// struct, pointer and slice fields, walking them the same way Add does
+// Children without id field or UId function can not be identified and hence are not deleted
+func (d *Dgraph) DeleteCascade(p interface{}) error {
+	return d.DeleteCascadeContext(context.Background(), p)
+}
+
+// This function deletes the node identified by GetUId(p) and its children, same as DeleteCascade
+// The mutation is cancelled when the given context is done
+func (d *Dgraph) DeleteCascadeContext(ctx context.Context, p interface{}) error {
+	return deleteContext(ctx, d.log(), d.client, d.ids, p, true)
+}
+
+// This function deletes the node identified by GetUId(p) and every node reachable through its
+// struct, pointer and slice fields, walking them the same way Add does
+// Children without id field or UId function can not be identified and hence are not deleted
+func DeleteCascade(c *client.Dgraph, p interface{}) error {
+	return DeleteCascadeContext(context.Background(), c, p)
+}
+
+// This function deletes the node identified by GetUId(p) and its children, same as DeleteCascade
+// The mutation is cancelled when the given context is done
+func DeleteCascadeContext(ctx context.Context, c *client.Dgraph, p interface{}) error {
+	return deleteContext(ctx, getLogger(), c, nil, p, true)
+}
+
+// This function deletes the relation named edge from the node of p to the node of target
+// Edge can be the go field name or dgraph name, if target is nil all the relations named edge are deleted
+func (d *Dgraph) DeleteEdge(p interface{}, edge string, target interface{}) error {
+	return d.DeleteEdgeContext(context.Background(), p, edge, target)
+}
+
+// This function deletes the relation named edge from the node of p to the node of target, same as DeleteEdge
+// The mutation is cancelled when the given context is done
+func (d *Dgraph) DeleteEdgeContext(ctx context.Context, p interface{}, edge string, target interface{}) error {
+	return deleteEdgeContext(ctx, d.log(), d.client, d.ids, p, edge, target)
+}
+
+// This function deletes the relation named edge from the node of p to the node of target
+// Edge can be the go field name or dgraph name, if target is nil all the relations named edge are deleted
+func DeleteEdge(c *client.Dgraph, p interface{}, edge string, target interface{}) error {
+	return DeleteEdgeContext(context.Background(), c, p, edge, target)
+}
+
+// This function deletes the relation named edge from the node of p to the node of target, same as DeleteEdge
+// The mutation is cancelled when the given context is done
+func DeleteEdgeContext(ctx context.Context, c *client.Dgraph, p interface{}, edge string, target interface{}) error {
+	return deleteEdgeContext(ctx, getLogger(), c, nil, p, edge, target)
+}
+
+// Internal function, deleting the relation with a single mutation
+func deleteEdgeContext(ctx context.Context, l Logger, c *client.Dgraph, ids IdentityStrategy, p interface{}, edge string, target interface{}) error {
+	r, sid, err := deleteEdgeRequest(l, c, ids, p, edge, target)
+	if err != nil {
+		return err
+	}
+	_, err = runRequest(c, ctx, r)
+	if err != nil {
+		l.Error("Mutation failed", "xid", sid, "error", err)
+		return &MutationError{Xid: sid, Cause: err}
+	}
+	return nil
+}
+
+// This function builds the request deleting the relation named edge from the node of p, it returns the _xid_ of the node too
+func deleteEdgeRequest(l Logger, c *client.Dgraph, ids IdentityStrategy, p interface{}, edge string, target interface{}) (*client.Req, string, error) {
+	if !isStructPtr(p) || (target != nil && !isStructPtr(target)) {
+		return nil, "", ErrInvalidTarget
+	}
+	f, ok := lookupField(reflect.TypeOf(p).Elem(), edge)
+	if !ok {
+		return nil, "", fmt.Errorf("%s does not have field %s", reflect.TypeOf(p).Elem().Name(), edge)
+	}
+	sid, uid, err := knownIdentity(ids, p)
+	if err != nil {
+		return nil, "", err
+	}
+	r := new(client.Req)
+	snode := c.NodeUid(uid)
+	var e client.Edge
+	if target == nil {
+		e = snode.Edge(getFieldName(f))
+		err = e.Delete()
+		if err != nil {
+			return nil, "", err
+		}
+	} else {
+		_, tuid, err := knownIdentity(ids, target)
+		if err != nil {
+			return nil, "", err
+		}
+		e = snode.ConnectTo(getFieldName(f), c.NodeUid(tuid))
+	}
+	l.Debug("Deleting edge", "xid", sid, "edge", getFieldName(f))
+	err = r.Delete(e)
+	if err != nil {
+		return nil, "", err
+	}
+	return r, sid, nil
+}
+
+// Internal function, deleting the node with a single mutation
+func deleteContext(ctx context.Context, l Logger, c *client.Dgraph, ids IdentityStrategy, p interface{}, cascade bool) error {
+	r, err := deleteRequest(l, c, ids, p, cascade)
+	if err != nil {
+		return err
+	}
+	_, err = runRequest(c, ctx, r)
+	if err != nil {
+		sid, _, _ := identity(ids, p)
+		l.Error("Mutation failed", "xid", sid, "error", err)
+		return &MutationError{Xid: sid, Cause: err}
+	}
+	return nil
+}
+
+// This function builds the request deleting the node of p, along with the nodes of its children if cascade is true
+func deleteRequest(l Logger, c *client.Dgraph, ids IdentityStrategy, p interface{}, cascade bool) (*client.Req, error) {
+	if !isStructPtr(p) {
+		return nil, ErrInvalidTarget
+	}
+	r := new(client.Req)
+	err := del(l, c, r, ids, p, cascade, map[uint64]bool{})
+	if err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// Internal function, adding deletion of the node of p into the request
+// If cascade is true, nodes of struct, pointer and slice fields are deleted too
+func del(l Logger, c *client.Dgraph, r *client.Req, ids IdentityStrategy, p interface{}, cascade bool, visited map[uint64]bool) error {
+	sid, uid, err := knownIdentity(ids, p)
+	if err != nil {
+		return err
+	}
+	if visited[uid] {
+		return nil
+	}
+	visited[uid] = true
+	l.Debug("Deleting node", "xid", sid)
+	snode := c.NodeUid(uid)
+	err = r.Delete(snode.Delete())
+	if err != nil {
+		return err
+	}
+	// Nodes storing the map fields are owned by this node
+	t := reflect.TypeOf(p)
+	for _, f := range nodeFields(t.Elem()) {
+		fname := getFieldName(f)
+		if _, ok := getFieldOptions(f)["json"]; ok || fname == "-" || !isMapType(f.Type) {
+			continue
+		}
+		mnode := mapNode(c, uid, fname)
+		err = r.Delete(mnode.Delete())
+		if err != nil {
+			return err
+		}
+	}
+	if !cascade {
+		return nil
+	}
+	v := reflect.ValueOf(p)
+	for _, f := range nodeFields(t.Elem()) {
+		fv, ok := fieldValue(v.Elem(), f.Index, false)
+		if !ok {
+			continue
+		}
+		fname := getFieldName(f)
+		if fname == "-" || isCountField(fname) || isPrimitiveType(f.Type) {
+			continue
+		}
+		// Skip zero values
+		if IsZero(fv) {
+			continue
+		}
+		switch fv.Kind() {
+		case reflect.Slice:
+			for j := 0; j < fv.Len(); j++ {
+				switch fv.Index(j).Kind() {
+				case reflect.Struct:
+					err = del(l, c, r, ids, fv.Index(j).Addr().Interface(), cascade, visited)
+				case reflect.Ptr:
+					if fv.Index(j).IsNil() || isPrimitiveType(fv.Index(j).Type()) {
+						continue
+					}
+					err = del(l, c, r, ids, fv.Index(j).Interface(), cascade, visited)
+				}
+				if err != nil {
+					return err
+				}
+			}
+		case reflect.Struct:
+			err = del(l, c, r, ids, fv.Addr().Interface(), cascade, visited)
+		case reflect.Ptr:
+			err = del(l, c, r, ids, fv.Interface(), cascade, visited)
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
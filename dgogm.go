@@ -1,13 +1,16 @@
 package dgogm
 
 import (
+	"fmt"
+	"math"
 	"reflect"
+	"sort"
+	"strconv"
 
 	"context"
 
 	"github.com/dgraph-io/dgraph/client"
 	"github.com/dgraph-io/dgraph/protos"
-	"github.com/pkg/errors"
 )
 
 // This function adds the given pointer to struct into the Dgraph
@@ -18,8 +21,17 @@ import (
 // 4. This library looks for dgraph tags for the field, if they are not available, they go for JSON tags, if that is not available it goes for field names
 // 5. If the field is a primitive type, its added as a predicate to the given node
 // 6. If the field is a struct or pointer to struct then a new relation node is added
+// The whole object graph is sent as a single mutation request, dgraph 0.8 does not apply it atomically though
+// Maps of the existing nodes are cleared with a request fired before it, and maps of the new nodes created with
+// ServerAssigned are written with a request fired after it, a failure in between loses the map
 func (d *Dgraph) Add(p interface{}) error {
-	return Add(d.client, p)
+	return d.AddContext(context.Background(), p)
+}
+
+// This function adds the given pointer to struct into the Dgraph, same as Add
+// The mutation is cancelled when the given context is done
+func (d *Dgraph) AddContext(ctx context.Context, p interface{}) error {
+	return addContext(ctx, d.log(), d.client, d.ids, p, modeAdd)
 }
 
 // This function adds the given pointer to struct into the Dgraph
@@ -30,14 +42,266 @@ func (d *Dgraph) Add(p interface{}) error {
 // 4. This library looks for dgraph tags for the field, if they are not available, they go for JSON tags, if that is not available it goes for field names
 // 5. If the field is a primitive type, its added as a predicate to the given node
 // 6. If the field is a struct or pointer to struct then a new relation node is added
+// The whole object graph is sent as a single mutation request, dgraph 0.8 does not apply it atomically though
+// Maps of the existing nodes are cleared with a request fired before it, and maps of the new nodes created with
+// ServerAssigned are written with a request fired after it, a failure in between loses the map
 func Add(c *client.Dgraph, p interface{}) error {
-	_, err := add(c, GetUId(p), p)
-	return err
+	return AddContext(context.Background(), c, p)
+}
+
+// This function adds the given pointer to struct into the Dgraph, same as Add
+// The mutation is cancelled when the given context is done
+func AddContext(ctx context.Context, c *client.Dgraph, p interface{}) error {
+	return addContext(ctx, getLogger(), c, nil, p, modeAdd)
+}
+
+// This function updates the node of the given pointer to struct in the Dgraph
+// Unlike Add, empty strings, nil pointers, zero structs, slices and maps clear the predicates, false and 0 are written
+// Fields tagged omitempty and unset Null fields are left untouched
+// If fields are given, only those predicates are written and nested structs are connected without being written
+// Slices are replaced by clearing them with a request fired before the mutation, a failing mutation loses them
+func (d *Dgraph) Update(p interface{}, fields ...string) error {
+	return d.UpdateContext(context.Background(), p, fields...)
+}
+
+// This function updates the node of the given pointer to struct in the Dgraph, same as Update
+// The mutation is cancelled when the given context is done
+func (d *Dgraph) UpdateContext(ctx context.Context, p interface{}, fields ...string) error {
+	return updateContext(ctx, d.log(), d.client, d.ids, p, fields)
+}
+
+// This function updates the node of the given pointer to struct in the Dgraph
+// Unlike Add, empty strings, nil pointers, zero structs, slices and maps clear the predicates, false and 0 are written
+// Fields tagged omitempty and unset Null fields are left untouched
+// If fields are given, only those predicates are written and nested structs are connected without being written
+// Slices are replaced by clearing them with a request fired before the mutation, a failing mutation loses them
+func Update(c *client.Dgraph, p interface{}, fields ...string) error {
+	return UpdateContext(context.Background(), c, p, fields...)
+}
+
+// This function updates the node of the given pointer to struct in the Dgraph, same as Update
+// The mutation is cancelled when the given context is done
+func UpdateContext(ctx context.Context, c *client.Dgraph, p interface{}, fields ...string) error {
+	return updateContext(ctx, getLogger(), c, nil, p, fields)
+}
+
+// This function writes the given values into the node of the given pointer to struct in the Dgraph
+// Keys are the go field names or dgraph names, nil values delete the predicates
+// Values are set into the struct too with nil zeroing the field once written, nested structs are connected without being written
+// Slices are replaced by clearing them with a request fired before the mutation, a failing mutation loses them
+func (d *Dgraph) Patch(p interface{}, values map[string]interface{}) error {
+	return d.PatchContext(context.Background(), p, values)
+}
+
+// This function writes the given values into the node of the given pointer to struct in the Dgraph, same as Patch
+// The mutation is cancelled when the given context is done
+func (d *Dgraph) PatchContext(ctx context.Context, p interface{}, values map[string]interface{}) error {
+	return patchContext(ctx, d.log(), d.client, d.ids, p, values)
+}
+
+// This function writes the given values into the node of the given pointer to struct in the Dgraph
+// Keys are the go field names or dgraph names, nil values delete the predicates
+// Values are set into the struct too with nil zeroing the field once written, nested structs are connected without being written
+// Slices are replaced by clearing them with a request fired before the mutation, a failing mutation loses them
+func Patch(c *client.Dgraph, p interface{}, values map[string]interface{}) error {
+	return PatchContext(context.Background(), c, p, values)
+}
+
+// This function writes the given values into the node of the given pointer to struct in the Dgraph, same as Patch
+// The mutation is cancelled when the given context is done
+func PatchContext(ctx context.Context, c *client.Dgraph, p interface{}, values map[string]interface{}) error {
+	return patchContext(ctx, getLogger(), c, nil, p, values)
+}
+
+// Internal function, updating the given fields of the object with a single mutation, along with the clearing request
+// All the fields are updated if none is given
+func updateContext(ctx context.Context, l Logger, c *client.Dgraph, ids IdentityStrategy, p interface{}, fields []string) error {
+	r, w, sid, err := updateRequest(l, c, ids, p, fields)
+	if err != nil {
+		return err
+	}
+	return run(ctx, l, c, r, sid, w)
+}
+
+// This function builds the request updating the given fields of the object, it returns the _xid_ of the node too
+func updateRequest(l Logger, c *client.Dgraph, ids IdentityStrategy, p interface{}, fields []string) (*client.Req, *write, string, error) {
+	if len(fields) == 0 {
+		return addRequest(l, c, ids, p, modeUpdate)
+	}
+	if !isStructPtr(p) {
+		return nil, nil, "", ErrInvalidTarget
+	}
+	w := newWrite(modePatch, ids)
+	sid, uid, err := knownIdentity(ids, p)
+	if err != nil {
+		return nil, nil, "", err
+	}
+	r := new(client.Req)
+	snode := c.NodeUid(uid)
+	err = setXid(r, snode, sid)
+	if err != nil {
+		return nil, nil, "", err
+	}
+	for _, name := range fields {
+		f, err := writableField(p, name)
+		if err != nil {
+			return nil, nil, "", err
+		}
+		fv, ok := fieldValue(reflect.ValueOf(p).Elem(), f.Index, false)
+		if !ok {
+			// Field of a nil embedded pointer
+			err = deletePredicate(l, r, snode, getFieldName(f))
+		} else {
+			err = addField(l, c, r, w, snode, f, fv)
+		}
+		if err != nil {
+			return nil, nil, "", err
+		}
+	}
+	return r, w, sid, nil
+}
+
+// Internal function, patching the object with a single mutation, along with the clearing request
+func patchContext(ctx context.Context, l Logger, c *client.Dgraph, ids IdentityStrategy, p interface{}, values map[string]interface{}) error {
+	r, w, sid, err := patchRequest(l, c, ids, p, values)
+	if err != nil {
+		return err
+	}
+	return run(ctx, l, c, r, sid, w)
+}
+
+// This function builds the request writing the given values into the node of the object
+// It returns the _xid_ of the node too
+func patchRequest(l Logger, c *client.Dgraph, ids IdentityStrategy, p interface{}, values map[string]interface{}) (*client.Req, *write, string, error) {
+	if !isStructPtr(p) {
+		return nil, nil, "", ErrInvalidTarget
+	}
+	w := newWrite(modePatch, ids)
+	sid, uid, err := knownIdentity(ids, p)
+	if err != nil {
+		return nil, nil, "", err
+	}
+	r := new(client.Req)
+	snode := c.NodeUid(uid)
+	err = setXid(r, snode, sid)
+	if err != nil {
+		return nil, nil, "", err
+	}
+	// Values are set into the struct only after the request succeeds, so they are converted into new values here
+	names := make([]string, 0, len(values))
+	for name := range values {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		f, err := writableField(p, name)
+		if err != nil {
+			return nil, nil, "", err
+		}
+		if values[name] == nil {
+			// Field is zeroed in the struct too
+			w.patched = append(w.patched, patched{p: p, field: f, value: reflect.Zero(f.Type)})
+			err = deletePredicate(l, r, snode, getFieldName(f))
+			if err != nil {
+				return nil, nil, "", err
+			}
+			continue
+		}
+		val, ok := convertTo(values[name], f.Type)
+		if !ok {
+			return nil, nil, "", ErrUnsupportedType{Field: f.Name, Type: reflect.TypeOf(values[name])}
+		}
+		fv := reflect.New(f.Type).Elem()
+		fv.Set(val)
+		err = addField(l, c, r, w, snode, f, fv)
+		if err != nil {
+			return nil, nil, "", err
+		}
+		w.patched = append(w.patched, patched{p: p, field: f, value: fv})
+	}
+	return r, w, sid, nil
+}
+
+// This function returns the field of p with the given name, which can be written into dgraph
+func writableField(p interface{}, name string) (reflect.StructField, error) {
+	t := reflect.TypeOf(p).Elem()
+	f, ok := lookupField(t, name)
+	if !ok {
+		return f, fmt.Errorf("%s does not have field %s", t.Name(), name)
+	}
+	if _, ok := getFieldOptions(f)["readonly"]; ok || isCountField(getFieldName(f)) {
+		return f, fmt.Errorf("field %s of %s is readonly", name, t.Name())
+	}
+	return f, nil
+}
+
+// This function fires the mutation request for the node with the given _xid_
+// Clearing request is fired before it, and the maps of the new nodes are written with a request fired after it
+// Uids assigned to the blank nodes are written back into their structs
+func run(ctx context.Context, l Logger, c *client.Dgraph, r *client.Req, sid string, w *write) error {
+	if w.clears != nil {
+		_, err := runRequest(c, ctx, w.clears)
+		if err != nil {
+			l.Error("Mutation failed", "xid", sid, "error", err)
+			return &MutationError{Xid: sid, Cause: err}
+		}
+	}
+	resp, err := runRequest(c, ctx, r)
+	if err != nil {
+		l.Error("Mutation failed", "xid", sid, "error", err)
+		return &MutationError{Xid: sid, Cause: err}
+	}
+	w.assigned(resp)
+	w.patch()
+	mr, err := w.mapRequest(l, c)
+	if err != nil || mr == nil {
+		return err
+	}
+	_, err = runRequest(c, ctx, mr)
+	if err != nil {
+		l.Error("Mutation failed", "xid", sid, "error", err)
+		return &MutationError{Xid: sid, Cause: err}
+	}
+	return nil
+}
+
+// Internal function, adding the object into dgraph with a single mutation, along with the clearing and map requests
+func addContext(ctx context.Context, l Logger, c *client.Dgraph, ids IdentityStrategy, p interface{}, mode writeMode) error {
+	r, w, sid, err := addRequest(l, c, ids, p, mode)
+	if err != nil {
+		return err
+	}
+	return run(ctx, l, c, r, sid, w)
+}
+
+// This function builds the request adding the whole object graph, it returns the _xid_ of the root node too
+func addRequest(l Logger, c *client.Dgraph, ids IdentityStrategy, p interface{}, mode writeMode) (*client.Req, *write, string, error) {
+	if !isStructPtr(p) {
+		return nil, nil, "", ErrInvalidTarget
+	}
+	w := newWrite(mode, ids)
+	// Updated node should already exist
+	if w.mode != modeAdd {
+		_, _, err := knownIdentity(ids, p)
+		if err != nil {
+			return nil, nil, "", err
+		}
+	}
+	snode, sid, err := w.node(c, p)
+	if err != nil {
+		return nil, nil, "", err
+	}
+	r := new(client.Req)
+	_, err = add(l, c, r, w, snode, sid, p)
+	if err != nil {
+		return nil, nil, "", err
+	}
+	return r, w, sid, nil
 }
 
 // This function creates a find query
 func (dg *Dgraph) Find(s interface{}) *DgQuery {
-	return Find(dg.client, s)
+	return &DgQuery{client: dg.client, s: s, logger: dg.logger, ids: dg.ids}
 }
 
 // This function creates a find query
@@ -45,126 +309,442 @@ func Find(c *client.Dgraph, s interface{}) *DgQuery {
 	return &DgQuery{client: c, s: s}
 }
 
+// This function creates a find query for all the nodes matching the filters given with Where
+// s should be a pointer to slice of structs or pointer to slice of pointers to structs
+func (dg *Dgraph) FindAll(s interface{}) *DgQuery {
+	return &DgQuery{client: dg.client, s: s, all: true, logger: dg.logger, ids: dg.ids}
+}
+
+// This function creates a find query for all the nodes matching the filters given with Where
+// s should be a pointer to slice of structs or pointer to slice of pointers to structs
+func FindAll(c *client.Dgraph, s interface{}) *DgQuery {
+	return &DgQuery{client: c, s: s, all: true}
+}
+
+// runRequest fires the given request with the client, passing the context of the caller to it
+var runRequest = (*client.Dgraph).Run
+
 // This function fires the given query on connected dgraph and fetches back the response
 // Does no alteration to the response
-func query(c *client.Dgraph, q string) ([]*protos.Node, error) {
+func query(ctx context.Context, l Logger, c *client.Dgraph, q string) ([]*protos.Node, error) {
 	req := new(client.Req)
-	Debug("Firing %s", q)
+	l.Debug("Firing query", "query", q)
 	req.SetQuery(q)
-	resp, err := c.Run(context.Background(), req)
+	resp, err := runRequest(c, ctx, req)
 	if err != nil {
-		return nil, err
+		l.Error("Query failed", "query", q, "error", err)
+		return nil, &QueryError{Query: q, Cause: err}
 	}
 	return resp.N, err
 }
 
-// Internal function, performing addition of the object into dgraph
-func add(c *client.Dgraph, sid string, p interface{}) (*client.Node, error) {
+// writeMode tells how the fields are written into the request
+type writeMode int
+
+const (
+	// Empty values are skipped
+	modeAdd writeMode = iota
+	// Empty values clear the predicates
+	modeUpdate
+	// Same as modeUpdate, but nested structs are only connected and not written
+	modePatch
+)
+
+// write holds the state of the objects being written with a single request
+type write struct {
+	mode writeMode
+	ids  IdentityStrategy
+	// Structs written with the request, their _uid_ and _xid_ are written back into them
+	nodes []*written
+	// Deletions of the values which are replaced by the request, these are fired before the request
+	// as dgraph does not order the deletions and the sets of a single mutation
+	clears *client.Req
+	// Maps of the nodes created with the request, these are written once dgraph assigned the uids of their owners
+	maps []*pendingMap
+	// Values written by Patch, these are set into the struct once the request succeeds
+	patched []patched
+}
+
+// patched is a value written into the field of a struct by Patch
+type patched struct {
+	p     interface{}
+	field reflect.StructField
+	value reflect.Value
+}
+
+// pendingMap is a map field of a node created with the request
+type pendingMap struct {
+	owner *written
+	field reflect.StructField
+	value reflect.Value
+}
+
+// written is a struct written with the request along with the identity of its node
+type written struct {
+	p   interface{}
+	xid string
+	uid uint64
+	// Name of the blank node, uid of which is assigned by dgraph
+	blank string
+	node  client.Node
+}
+
+// This function creates the state for writing objects with the given mode and identity strategy
+func newWrite(mode writeMode, ids IdentityStrategy) *write {
+	return &write{mode: mode, ids: ids}
+}
+
+// This function returns the node of p along with its _xid_, nodes not known yet are created as blank nodes
+func (w *write) node(c *client.Dgraph, p interface{}) (client.Node, string, error) {
+	n, err := w.track(p)
+	if err != nil {
+		return client.Node{}, "", err
+	}
+	if n.blank == "" {
+		n.node = c.NodeUid(n.uid)
+		return n.node, n.xid, nil
+	}
+	n.node, err = c.NodeBlank(n.blank)
+	if err != nil {
+		return client.Node{}, "", err
+	}
+	return n.node, n.xid, nil
+}
+
+// This function resolves the identity of p and records it for writing back after the request
+// Nodes not known yet are named after the hash of their _xid_, so that the same object gives the same blank node
+func (w *write) track(p interface{}) (*written, error) {
+	sid, uid, err := identity(w.ids, p)
+	if err != nil {
+		return nil, err
+	}
+	n := &written{p: p, xid: sid, uid: uid}
+	if uid == 0 {
+		n.blank = fmt.Sprintf("n%x", hash(sid))
+	}
+	w.nodes = append(w.nodes, n)
+	return n, nil
+}
+
+// This function returns the written struct of the given node if it's created with the request, nil otherwise
+func (w *write) created(snode client.Node) *written {
+	for _, n := range w.nodes {
+		if n.blank != "" && n.node.String() == snode.String() {
+			return n
+		}
+	}
+	return nil
+}
+
+// This function adds the deletion of the given edge, which is fired before the request
+func (w *write) clear(e client.Edge) error {
+	if w.clears == nil {
+		w.clears = new(client.Req)
+	}
+	return w.clears.Delete(e)
+}
+
+// This function clears all the values of the given predicate of snode before the request, so that the request replaces them
+func (w *write) replace(l Logger, snode client.Node, predicate string) error {
+	if w.clears == nil {
+		w.clears = new(client.Req)
+	}
+	return deletePredicate(l, w.clears, snode, predicate)
+}
+
+// This function writes the _uid_ and _xid_ of the nodes back into the fields of their structs tagged dgraph:"_uid_"
+// and dgraph:"_xid_", uids of the blank nodes are the ones assigned by dgraph
+// Dgraph returns the uids assigned to the blank nodes by their names, the client allocating them is asked otherwise
+func (w *write) assigned(resp *protos.Response) {
+	for _, n := range w.nodes {
+		uid := n.uid
+		if n.blank != "" {
+			if resp != nil && resp.AssignedUids[n.blank] != 0 {
+				uid = resp.AssignedUids[n.blank]
+			} else {
+				uid = assignedUid(n.node)
+			}
+			if uid == 0 {
+				continue
+			}
+			n.uid = uid
+		}
+		setUid(n.p, uid)
+		storeXid(n.p, n.xid)
+	}
+}
+
+// This function sets the values written by Patch into their structs, fields of nil embedded pointers are
+// allocated unless the field is zeroed
+func (w *write) patch() {
+	for _, pv := range w.patched {
+		fv, ok := fieldValue(reflect.ValueOf(pv.p).Elem(), pv.field.Index, !IsZero(pv.value))
+		if ok && fv.CanSet() {
+			fv.Set(pv.value)
+		}
+	}
+}
+
+// This function builds the request writing the maps of the nodes created with the request, nil if there is none
+// It's built once the uids assigned by dgraph are written back
+func (w *write) mapRequest(l Logger, c *client.Dgraph) (*client.Req, error) {
+	if len(w.maps) == 0 {
+		return nil, nil
+	}
+	r := new(client.Req)
+	for _, m := range w.maps {
+		if m.owner.uid == 0 {
+			return nil, fmt.Errorf("uid of %s is not assigned", m.owner.xid)
+		}
+		err := setMap(l, c, r, c.NodeUid(m.owner.uid), m.owner.uid, m.field, m.value)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return r, nil
+}
+
+// This function returns the uid of the given node, zero for the blank nodes which are not assigned yet
+func assignedUid(n client.Node) uint64 {
+	uid, _ := strconv.ParseUint(n.String(), 0, 64)
+	return uid
+}
+
+// Internal function, adding the object into the given request
+// Nested structs are added into the same request, request is not fired
+func add(l Logger, c *client.Dgraph, r *client.Req, w *write, snode client.Node, sid string, p interface{}) (*client.Node, error) {
 	// Get type info of p
 	t := reflect.TypeOf(p)
 	// Get value info of p
 	v := reflect.ValueOf(p)
-	Debug("sid is %s", sid)
-	Debug("------\n %v", p, t.String(), v.String())
-	var err error
-	// Creating request object
-	r := new(client.Req)
-	// Creating source node and process _xid_ to it
-	snode := c.NodeUid(hash(sid))
-	e := snode.Edge("_xid_")
-	e.SetValueString(sid)
-	err = r.Set(e)
+	l.Debug("Adding node", "xid", sid, "type", t.String())
+	// Adding _xid_ to the source node
+	err := setXid(r, snode, sid)
 	if err != nil {
 		return nil, err
 	}
 	// Ranging over the interface fields
-	for i := 0; i < v.Elem().NumField(); i++ {
-		fname := getFieldName(t.Elem().Field(i))
-		if fname == "-" {
+	for _, f := range nodeFields(t.Elem()) {
+		fv, ok := fieldValue(v.Elem(), f.Index, false)
+		if !ok {
 			continue
 		}
-		// Skip zero values
-		if IsZero(v.Elem().Field(i)) {
-			continue
+		err = addField(l, c, r, w, snode, f, fv)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return &snode, nil
+}
+
+// This function adds the _xid_ of the given node into the request
+func setXid(r *client.Req, snode client.Node, sid string) error {
+	e := snode.Edge("_xid_")
+	e.SetValueString(sid)
+	return r.Set(e)
+}
+
+// This function adds the given field of the node into the request
+func addField(l Logger, c *client.Dgraph, r *client.Req, w *write, snode client.Node, f reflect.StructField, fv reflect.Value) error {
+	fname := getFieldName(f)
+	// _uid_ and _xid_ are decided by the identity strategy
+	if fname == "-" || isCountField(fname) || fname == "_uid_" || fname == "_xid_" {
+		return nil
+	}
+	var err error
+	opts := getFieldOptions(f)
+	// Readonly fields are only loaded from dgraph
+	if _, ok := opts["readonly"]; ok {
+		return nil
+	}
+	// Null values are written only when set, and explicitly cleared ones are deleted
+	if n, ok := fv.Interface().(nullable); ok && (n.IsNull() || !n.IsSet()) {
+		if n.IsNull() {
+			return deletePredicate(l, r, snode, fname)
+		}
+		if _, ok := opts["required"]; ok {
+			return ErrRequired{Field: f.Name}
+		}
+		return nil
+	}
+	if IsZero(fv) {
+		if _, ok := opts["required"]; ok {
+			return ErrRequired{Field: f.Name}
+		}
+		// false and 0 are written unless the field is tagged omitempty, which is ignored while patching
+		if _, ok := opts["omitempty"]; ok && w.mode != modePatch {
+			return nil
+		}
+		// Other zero values can not be written, while updating they clear the predicate
+		if !isZeroWritable(fv.Kind()) {
+			if w.mode != modeAdd {
+				err = deletePredicate(l, r, snode, fname)
+				if err != nil {
+					return err
+				}
+			}
+			return nil
+		}
+	}
+	l.Debug("Adding edge", "edge", fname)
+	// Numbers and bools tagged with string option are stored as string
+	if _, ok := opts["string"]; ok {
+		if str, ok := formatString(reflect.Indirect(fv)); ok {
+			_, err = process(l, c, r, snode, f, reflect.ValueOf(str), w)
+			return err
+		}
+	}
+	// Scalars like []byte and time.Time are stored as a single predicate
+	if isScalarType(f.Type) {
+		se, err := process(l, c, r, snode, f, fv, w)
+		if err != nil {
+			return err
 		}
-		Debug("Adding edge %s", getFieldName(t.Elem().Field(i)))
-		switch v.Elem().Field(i).Kind() {
-		case reflect.Slice:
-			var tnode *client.Node
-			if v.Elem().Field(i).Len() == 0 {
-				return nil, nil
+		// Nothing is written for empty values, while updating they clear the predicate
+		if se == nil && w.mode != modeAdd {
+			err = deletePredicate(l, r, snode, fname)
+			if err != nil {
+				return err
 			}
-			// Check if this array contains a primitive kind of elements
-			if isPrimitiveType(v.Elem().Field(i).Index(0).Type()) {
-				// Then jsonify them and push them inside
-				Debug("Adding %s", ToJsonUnsafe(v.Elem().Field(i).Interface()))
-				_, err = process(c, r, snode, t.Elem().Field(i), reflect.ValueOf(ToJsonUnsafe(v.Elem().Field(i).Interface())))
+		}
+		return nil
+	}
+	switch fv.Kind() {
+	case reflect.Slice:
+		var tnode *client.Node
+		if fv.Len() == 0 {
+			if w.mode != modeAdd {
+				err = deletePredicate(l, r, snode, fname)
 				if err != nil {
-					return nil, err
+					return err
 				}
-				continue
 			}
-			for j := 0; j < v.Elem().Field(i).Len(); j++ {
-				switch v.Elem().Field(i).Index(j).Kind() {
-				case reflect.Struct:
-					tnode, err = add(c, GetUId(v.Elem().Field(i).Index(j).Addr().Interface()),
-						v.Elem().Field(i).Index(j).Addr().Interface())
-					if err != nil {
-						return nil, err
-					}
-					e = snode.ConnectTo(getFieldName(t.Elem().Field(i)), *tnode)
-					err = r.Set(e)
-					if err != nil {
-						return nil, err
-					}
-				case reflect.Ptr:
-					tnode, err = add(c, GetUId(v.Elem().Field(i).Index(j).Interface()),
-						v.Elem().Field(i).Index(j).Interface())
-					if err != nil {
-						return nil, err
-					}
-					if tnode == nil {
-						continue
-					}
-					e = snode.ConnectTo(getFieldName(t.Elem().Field(i)), *tnode)
-					err = r.Set(e)
-					if err != nil {
-						return nil, err
-					}
-				default:
-					return nil, errors.New("Does not support " + t.Elem().Field(i).Type.String())
+			return nil
+		}
+		// Check if this array contains a primitive kind of elements
+		if isPrimitiveType(fv.Index(0).Type()) {
+			// Legacy encoding, jsonify them and push them inside as a single string
+			if _, ok := opts["json"]; ok {
+				l.Debug("Adding slice as json", "edge", fname)
+				_, err = process(l, c, r, snode, f, reflect.ValueOf(ToJsonUnsafe(fv.Interface())), w)
+				return err
+			}
+			// Setting the list predicate only adds the values, so the existing ones are cleared while updating
+			if w.mode != modeAdd {
+				err = w.replace(l, snode, fname)
+				if err != nil {
+					return err
 				}
 			}
-		default:
-			_, err = process(c, r, snode, t.Elem().Field(i), v.Elem().Field(i))
+			// Each element is added as a value of the list predicate
+			for j := 0; j < fv.Len(); j++ {
+				elem := fv.Index(j)
+				// Empty values can not be stored in dgraph
+				if (elem.Kind() == reflect.Ptr && elem.IsNil()) || (reflect.Indirect(elem).Kind() == reflect.String && reflect.Indirect(elem).Len() == 0) {
+					continue
+				}
+				_, err = process(l, c, r, snode, f, elem, w)
+				if err != nil {
+					return err
+				}
+			}
+			return nil
+		}
+		// Same as the list predicates, existing edges are cleared while updating
+		if w.mode != modeAdd {
+			err = w.replace(l, snode, fname)
 			if err != nil {
-				return nil, err
+				return err
+			}
+		}
+		for j := 0; j < fv.Len(); j++ {
+			switch fv.Index(j).Kind() {
+			case reflect.Struct:
+				tnode, err = relate(l, c, r, w, fv.Index(j).Addr().Interface())
+				if err != nil {
+					return err
+				}
+				e := snode.ConnectTo(getFieldName(f), *tnode)
+				err = r.Set(e)
+				if err != nil {
+					return err
+				}
+			case reflect.Ptr:
+				tnode, err = relate(l, c, r, w, fv.Index(j).Interface())
+				if err != nil {
+					return err
+				}
+				if tnode == nil {
+					continue
+				}
+				e := snode.ConnectTo(getFieldName(f), *tnode)
+				err = r.Set(e)
+				if err != nil {
+					return err
+				}
+			default:
+				return ErrUnsupportedType{Field: f.Name, Type: f.Type}
 			}
 		}
+	case reflect.Map:
+		// Maps can be stored as json string
+		if _, ok := opts["json"]; ok {
+			l.Debug("Adding map as json", "edge", fname)
+			_, err = process(l, c, r, snode, f, reflect.ValueOf(ToJsonUnsafe(fv.Interface())), w)
+			return err
+		}
+		err = addMap(l, c, r, w, snode, f, fv)
+		if err != nil {
+			return err
+		}
+	default:
+		_, err = process(l, c, r, snode, f, fv, w)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// This function returns the node of the given nested struct, which is added into the request too unless patching
+func relate(l Logger, c *client.Dgraph, r *client.Req, w *write, p interface{}) (*client.Node, error) {
+	if w.mode == modePatch {
+		_, uid, err := knownIdentity(w.ids, p)
+		if err != nil {
+			return nil, err
+		}
+		tnode := c.NodeUid(uid)
+		return &tnode, nil
+	}
+	// Only the root is updated, nested structs can be partially loaded so their empty values are not cleared
+	if w.mode == modeUpdate {
+		w.mode = modeAdd
+		defer func() { w.mode = modeUpdate }()
 	}
-	_, err = c.Run(context.Background(), r)
+	tnode, sid, err := w.node(c, p)
 	if err != nil {
 		return nil, err
 	}
-	return &snode, nil
+	return add(l, c, r, w, tnode, sid, p)
 }
 
 // This function does the core processing of the fields
 // Detects the name of the field, type of the field, and decides how to attach it with
 // all the available information
-func process(c *client.Dgraph, r *client.Req, snode client.Node, field reflect.StructField, value reflect.Value) (*client.Edge, error) {
+func process(l Logger, c *client.Dgraph, r *client.Req, snode client.Node, field reflect.StructField, value reflect.Value, w *write) (*client.Edge, error) {
 	var e client.Edge
 	var err error
+	if isScalarType(value.Type()) {
+		return processScalar(r, snode, field, value)
+	}
 	switch value.Kind() {
 	case reflect.Ptr:
-		Debug(value.Elem().Kind().String())
 		// Checking if its pointer to primitve data type
 		if isPrimitiveType(value.Elem().Type()) {
 			// its pointer to primitive kind
-			return process(c, r, snode, field, value.Elem())
+			return process(l, c, r, snode, field, value.Elem(), w)
 		}
-		Debug("its ptr******", field.Type.Elem())
-		tnode, err := add(c, GetUId(value.Interface()), value.Interface())
+		tnode, err := relate(l, c, r, w, value.Interface())
 		if err != nil {
 			return nil, err
 		}
@@ -177,8 +757,7 @@ func process(c *client.Dgraph, r *client.Req, snode client.Node, field reflect.S
 			return nil, err
 		}
 	case reflect.Struct:
-		Debug("its struct******", field.Type)
-		tnode, err := add(c, GetUId(value.Addr().Interface()), value.Addr().Interface())
+		tnode, err := relate(l, c, r, w, value.Addr().Interface())
 		if err != nil {
 			return nil, err
 		}
@@ -202,8 +781,12 @@ func process(c *client.Dgraph, r *client.Req, snode client.Node, field reflect.S
 		}
 	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
 		// This includes all primitive types
+		// Values above math.MaxInt64 can not be stored as int
+		if value.Uint() > math.MaxInt64 {
+			return nil, ErrOverflow{Field: field.Name, Value: value.Uint()}
+		}
 		e = snode.Edge(getFieldName(field))
-		err = setVal(&e, value.Uint())
+		err = setVal(&e, int64(value.Uint()))
 		if err != nil {
 			return nil, err
 		}
@@ -222,7 +805,7 @@ func process(c *client.Dgraph, r *client.Req, snode client.Node, field reflect.S
 			return nil, err
 		}
 	case reflect.String:
-		Debug("Adding string %s => %s", getFieldName(field), value.String())
+		l.Debug("Adding string", "edge", getFieldName(field), "value", value.String())
 		e = snode.Edge(getFieldName(field))
 		err = setVal(&e, value.String())
 		if err != nil {
@@ -242,6 +825,127 @@ func process(c *client.Dgraph, r *client.Req, snode client.Node, field reflect.S
 		if err != nil {
 			return nil, err
 		}
+	default:
+		return nil, ErrUnsupportedType{Field: field.Name, Type: field.Type}
+	}
+	return &e, nil
+}
+
+// This function adds the scalars which are not of primitive kinds
+// time.Time is stored as datetime, GeoPoint as geo, []byte as bytes and json.RawMessage as string
+// Types implementing ValueMarshaler or encoding.TextMarshaler are stored as the value they marshal to
+func processScalar(r *client.Req, snode client.Node, field reflect.StructField, value reflect.Value) (*client.Edge, error) {
+	val, err := marshalValue(value)
+	if err != nil {
+		if ut, ok := err.(ErrUnsupportedType); ok {
+			ut.Field = field.Name
+			return nil, ut
+		}
+		return nil, err
+	}
+	// Empty values can not be stored in dgraph
+	if s, ok := val.(string); val == nil || (ok && s == "") {
+		return nil, nil
+	}
+	e := snode.Edge(getFieldName(field))
+	err = setVal(&e, val)
+	if err != nil {
+		if oe, ok := err.(ErrOverflow); ok {
+			oe.Field = field.Name
+			return nil, oe
+		}
+		return nil, err
+	}
+	err = r.Set(e)
+	if err != nil {
+		return nil, err
 	}
 	return &e, nil
 }
+
+// This function adds the given map as a node connected to snode, each key of the map being a predicate of that node
+// _xid_ of the map node is derived from the uid of the owner, so that adding it again updates the same node
+// Maps of the nodes created with the request are written with another request, once dgraph assigned their uids
+// Predicates of the map node are cleared first, so that the keys removed from the map are deleted too
+// Map nodes of the nodes created with the request are not cleared, as they can not exist yet
+func addMap(l Logger, c *client.Dgraph, r *client.Req, w *write, snode client.Node, field reflect.StructField, value reflect.Value) error {
+	if !isMapType(value.Type()) {
+		return ErrUnsupportedType{Field: field.Name, Type: field.Type}
+	}
+	keys := value.MapKeys()
+	sort.Slice(keys, func(i, j int) bool { return keys[i].String() < keys[j].String() })
+	for _, k := range keys {
+		if !isPredicateName(k.String()) {
+			return fmt.Errorf("%s has invalid key %q, keys of the maps should be valid predicate names", field.Name, k.String())
+		}
+	}
+	owner := assignedUid(snode)
+	if owner == 0 {
+		n := w.created(snode)
+		if n == nil {
+			return fmt.Errorf("%s is not known, its map %s can not be written", snode.String(), field.Name)
+		}
+		w.maps = append(w.maps, &pendingMap{owner: n, field: field, value: value})
+		return nil
+	}
+	if w.created(snode) == nil {
+		mnode := mapNode(c, owner, getFieldName(field))
+		err := w.clear(mnode.Delete())
+		if err != nil {
+			return err
+		}
+	}
+	return setMap(l, c, r, snode, owner, field, value)
+}
+
+// This function returns the node storing the map field of the node with the given uid
+func mapNode(c *client.Dgraph, uid uint64, fname string) client.Node {
+	return c.NodeUid(hash(mapXid(uid, fname)))
+}
+
+// This function adds the keys of the given map into the map node of the owner, and connects snode to it
+func setMap(l Logger, c *client.Dgraph, r *client.Req, snode client.Node, owner uint64, field reflect.StructField, value reflect.Value) error {
+	keys := value.MapKeys()
+	sort.Slice(keys, func(i, j int) bool { return keys[i].String() < keys[j].String() })
+	mid := mapXid(owner, getFieldName(field))
+	l.Debug("Adding map", "xid", mid)
+	mnode := c.NodeUid(hash(mid))
+	e := mnode.Edge("_xid_")
+	e.SetValueString(mid)
+	err := r.Set(e)
+	if err != nil {
+		return err
+	}
+	for _, k := range keys {
+		val, err := marshalValue(value.MapIndex(k))
+		if err != nil {
+			return err
+		}
+		// Empty values can not be stored in dgraph
+		if s, ok := val.(string); val == nil || (ok && s == "") {
+			continue
+		}
+		e = mnode.Edge(k.String())
+		err = setVal(&e, val)
+		if err != nil {
+			return err
+		}
+		err = r.Set(e)
+		if err != nil {
+			return err
+		}
+	}
+	e = snode.ConnectTo(getFieldName(field), mnode)
+	return r.Set(e)
+}
+
+// This function adds deletion of all the values of the given predicate of snode into the request
+func deletePredicate(l Logger, r *client.Req, snode client.Node, predicate string) error {
+	l.Debug("Clearing edge", "edge", predicate)
+	e := snode.Edge(predicate)
+	err := e.Delete()
+	if err != nil {
+		return err
+	}
+	return r.Delete(e)
+}
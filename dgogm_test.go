@@ -1,8 +1,12 @@
 package dgogm_test
 
 import (
+	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"testing"
+	"time"
 
 	"log"
 
@@ -33,6 +37,72 @@ func TestDgraph_FindById(t *testing.T) {
 	fmt.Printf("%v %v", d, *d.Color)
 }
 
+func TestDgraph_Close(t *testing.T) {
+	dg, err := dgogm.Connect([]string{"127.0.0.1:9080"})
+	if err != nil {
+		t.Fail()
+	}
+	err = dg.Close()
+	if err != nil {
+		t.Fail()
+	}
+}
+
+func TestDgraph_FindByIdWithFields(t *testing.T) {
+	dg, err := dgogm.Connect([]string{"127.0.0.1:9080"})
+	if err != nil {
+		t.Fail()
+	}
+	d := new(Dog)
+	d.Id = 1
+	d.Nicknames = []string{"untouched"}
+	err = dg.Find(d).Id(1).Fields("name", "likes_places.name").Execute()
+	if err != nil {
+		t.Fail()
+	}
+	if d.Name != "jarvis" || len(d.Likes) != 2 || d.Color != nil || d.Nicknames[0] != "untouched" {
+		t.Fail()
+	}
+	fmt.Printf("%v", d)
+}
+
+func TestDgraph_FindAll(t *testing.T) {
+	dg, err := dgogm.Connect([]string{"127.0.0.1:9080"})
+	if err != nil {
+		t.Fail()
+	}
+	dogs := []*Dog{}
+	err = dg.FindAll(&dogs).Where(dgogm.Eq("name", "jarvis")).Execute()
+	if err != nil || len(dogs) == 0 {
+		t.Fail()
+	}
+	fmt.Printf("%v", dogs)
+}
+
+func TestDgraph_Count(t *testing.T) {
+	dg, err := dgogm.Connect([]string{"127.0.0.1:9080"})
+	if err != nil {
+		t.Fail()
+	}
+	count, err := dg.FindAll(&[]Dog{}).Where(dgogm.Eq("name", "jarvis")).Count()
+	if err != nil || count == 0 {
+		t.Fail()
+	}
+	fmt.Printf("%d", count)
+}
+
+func TestDgraph_Max(t *testing.T) {
+	dg, err := dgogm.Connect([]string{"127.0.0.1:9080"})
+	if err != nil {
+		t.Fail()
+	}
+	max, err := dg.FindAll(&[]Place{}).Where(dgogm.Has("name")).Max("uid")
+	if err != nil || max == 0 {
+		t.Fail()
+	}
+	fmt.Printf("%f", max)
+}
+
 func TestDgraph_FindById1(t *testing.T) {
 	dg, err := dgogm.Connect([]string{"127.0.0.1:9080"})
 	if err != nil {
@@ -363,3 +433,407 @@ func TestDgraph_AddWithRelationSliceWithPrimitiveDtPointer(t *testing.T) {
 		t.Fail()
 	}
 }
+
+type Dog10 struct {
+	Id       int             `dgraph:"uid"`
+	Name     string          `dgraph:"name"`
+	Born     time.Time       `dgraph:"born"`
+	Vaccine  *time.Time      `dgraph:"vaccinated_at"`
+	Chip     []byte          `dgraph:"chip"`
+	Home     *dgogm.GeoPoint `dgraph:"home"`
+	Metadata json.RawMessage `dgraph:"metadata"`
+}
+
+func TestDgraph_AddWithScalars(t *testing.T) {
+	dg, err := dgogm.Connect([]string{"127.0.0.1:9080"})
+	if err != nil {
+		t.Fail()
+	}
+	born := time.Date(2015, 3, 12, 10, 30, 0, 0, time.UTC)
+	d := new(Dog10)
+	d.Id = 1
+	d.Name = "jarvis"
+	d.Born = born
+	d.Vaccine = &born
+	d.Chip = []byte("985112345678901")
+	d.Home = &dgogm.GeoPoint{Type: "Feature", Geometry: dgogm.GeoGeometry{Type: "Point", Coordinates: []float64{72.87, 19.07}}}
+	d.Metadata = json.RawMessage(`{"breed":"labrador"}`)
+	err = dg.Add(d)
+	if err != nil {
+		log.Println(err.Error())
+		t.Fail()
+	}
+	f := new(Dog10)
+	err = dg.Find(f).Id(1).Execute()
+	if err != nil {
+		t.Fail()
+	}
+	if !f.Born.Equal(born) || f.Vaccine == nil || !f.Vaccine.Equal(born) || string(f.Chip) != string(d.Chip) ||
+		f.Home == nil || f.Home.Geometry.Type != "Point" || string(f.Metadata) != string(d.Metadata) {
+		t.Fail()
+	}
+}
+
+type Breed int
+
+func (b Breed) MarshalText() ([]byte, error) {
+	switch b {
+	case 1:
+		return []byte("labrador"), nil
+	case 2:
+		return []byte("beagle"), nil
+	}
+	return nil, fmt.Errorf("unknown breed %d", b)
+}
+
+func (b *Breed) UnmarshalText(text []byte) error {
+	switch string(text) {
+	case "labrador":
+		*b = 1
+	case "beagle":
+		*b = 2
+	default:
+		return fmt.Errorf("unknown breed %s", text)
+	}
+	return nil
+}
+
+type Dog11 struct {
+	Id    int    `dgraph:"uid"`
+	Name  string `dgraph:"name"`
+	Breed Breed  `dgraph:"breed"`
+}
+
+func TestDgraph_AddWithTextMarshaler(t *testing.T) {
+	dg, err := dgogm.Connect([]string{"127.0.0.1:9080"})
+	if err != nil {
+		t.Fail()
+	}
+	d := new(Dog11)
+	d.Id = 1
+	d.Name = "jarvis"
+	d.Breed = 2
+	err = dg.Add(d)
+	if err != nil {
+		log.Println(err.Error())
+		t.Fail()
+	}
+	f := new(Dog11)
+	err = dg.Find(f).Id(1).Execute()
+	if err != nil || f.Breed != 2 {
+		t.Fail()
+	}
+}
+
+func TestDgraph_AddWithMaps(t *testing.T) {
+	dg, err := dgogm.Connect([]string{"127.0.0.1:9080"})
+	if err != nil {
+		t.Fail()
+	}
+	p := new(Product)
+	p.Id = "collar"
+	p.Name = "collar"
+	p.Attrs = map[string]string{"material": "leather", "size": "m"}
+	p.Prices = map[string]int{"inr": 450, "usd": 6}
+	err = dg.Add(p)
+	if err != nil {
+		log.Println(err.Error())
+		t.Fail()
+	}
+	f := new(Product)
+	f.Id = "collar"
+	err = dg.Find(f).Execute()
+	if err != nil || f.Attrs["material"] != "leather" || f.Attrs["size"] != "m" || f.Prices["inr"] != 450 {
+		t.Fail()
+	}
+}
+
+func TestDgraph_AddWithTagOptions(t *testing.T) {
+	dg, err := dgogm.Connect([]string{"127.0.0.1:9080"})
+	if err != nil {
+		t.Fail()
+	}
+	s := new(Settings)
+	s.Id = "notifications"
+	err = dg.Add(s)
+	if !errors.As(err, &dgogm.ErrRequired{}) {
+		t.Fail()
+	}
+	s.Email = "admin@example.com"
+	s.Retries = 0
+	s.Views = 10
+	err = dg.Add(s)
+	if err != nil {
+		log.Println(err.Error())
+		t.Fail()
+	}
+	f := new(Settings)
+	f.Id = "notifications"
+	f.Retries = 3
+	err = dg.Find(f).Execute()
+	if err != nil || f.Retries != 0 || f.Email != s.Email || f.Views != 0 {
+		t.Fail()
+	}
+}
+
+func TestDgraph_Update(t *testing.T) {
+	dg, err := dgogm.Connect([]string{"127.0.0.1:9080"})
+	if err != nil {
+		t.Fail()
+	}
+	p := new(Profile)
+	p.Id = "jarvis"
+	p.Nickname = dgogm.NullOf("chotu")
+	p.Active = dgogm.NullOf(true)
+	p.Score = dgogm.NullOf(4.5)
+	err = dg.Add(p)
+	if err != nil {
+		log.Println(err.Error())
+		t.Fail()
+	}
+	// Clearing the nickname, writing false and leaving the score untouched
+	u := new(Profile)
+	u.Id = "jarvis"
+	u.Nickname = dgogm.Cleared[string]()
+	u.Active = dgogm.NullOf(false)
+	err = dg.Update(u)
+	if err != nil {
+		log.Println(err.Error())
+		t.Fail()
+	}
+	f := new(Profile)
+	f.Id = "jarvis"
+	err = dg.Find(f).Execute()
+	if err != nil || f.Nickname.IsSet() {
+		t.Fail()
+	}
+	if active, ok := f.Active.Get(); !ok || active {
+		t.Fail()
+	}
+	if score, ok := f.Score.Get(); !ok || score != 4.5 {
+		t.Fail()
+	}
+}
+
+func TestDgraph_UpdateFields(t *testing.T) {
+	dg, err := dgogm.Connect([]string{"127.0.0.1:9080"})
+	if err != nil {
+		t.Fail()
+	}
+	d := new(Dog)
+	d.Id = 1
+	d.Name = "friday"
+	d.Color = nil
+	err = dg.Update(d, "name", "Color")
+	if err != nil {
+		log.Println(err.Error())
+		t.Fail()
+	}
+	f := new(Dog)
+	f.Id = 1
+	err = dg.Find(f).Fields("name", "color", "likes_places").Execute()
+	if err != nil || f.Name != "friday" || f.Color != nil || len(f.Likes) == 0 {
+		t.Fail()
+	}
+}
+
+func TestDgraph_Patch(t *testing.T) {
+	dg, err := dgogm.Connect([]string{"127.0.0.1:9080"})
+	if err != nil {
+		t.Fail()
+	}
+	d := new(Dog)
+	d.Id = 1
+	err = dg.Patch(d, map[string]interface{}{"name": "jarvis", "color": "white", "nicknames": nil})
+	if err != nil {
+		log.Println(err.Error())
+		t.Fail()
+	}
+	if d.Name != "jarvis" || d.Color == nil || *d.Color != "white" {
+		t.Fail()
+	}
+	err = dg.Patch(d, map[string]interface{}{"age": 2})
+	if err == nil {
+		t.Fail()
+	}
+}
+
+type Owner1 struct {
+	Id    string `dgraph:"uid"`
+	Email string `dgraph:"email,index=exact"`
+	Name  string `dgraph:"name"`
+}
+
+func TestDgraph_Upsert(t *testing.T) {
+	dg, err := dgogm.Connect([]string{"127.0.0.1:9080"})
+	if err != nil {
+		t.Fail()
+	}
+	o := new(Owner1)
+	o.Id = "akshay"
+	o.Email = "akshay@example.com"
+	o.Name = "Akshay"
+	err = dg.Upsert(o, "email")
+	if err != nil {
+		log.Println(err.Error())
+		t.Fail()
+	}
+	// Another service knowing only the email updates the same node
+	u := new(Owner1)
+	u.Id = "someone_else"
+	u.Email = "akshay@example.com"
+	u.Name = "Akshay Deo"
+	err = dg.Upsert(u, "Email")
+	if err != nil {
+		log.Println(err.Error())
+		t.Fail()
+	}
+	var owners []*Owner1
+	err = dg.FindAll(&owners).Where(dgogm.Eq("email", "akshay@example.com")).Execute()
+	if err != nil || len(owners) != 1 || owners[0].Name != "Akshay Deo" {
+		t.Fail()
+	}
+	err = dg.Upsert(new(Owner1), "email")
+	if !errors.As(err, &dgogm.ErrRequired{}) {
+		t.Fail()
+	}
+}
+
+type Walker struct {
+	Uid  uint64 `dgraph:"_uid_"`
+	Name string `dgraph:"name"`
+}
+
+type Dog12 struct {
+	Uid    uint64  `dgraph:"_uid_"`
+	Name   string  `dgraph:"name"`
+	Walker *Walker `dgraph:"walker"`
+}
+
+func TestDgraph_AddWithServerAssignedIdentity(t *testing.T) {
+	dg, err := dgogm.Connect([]string{"127.0.0.1:9080"})
+	if err != nil {
+		t.Fail()
+	}
+	dg.SetIdentityStrategy(dgogm.ServerAssigned)
+	d := &Dog12{Name: "jarvis", Walker: &Walker{Name: "tony"}}
+	err = dg.Add(d)
+	if err != nil {
+		log.Println(err.Error())
+		t.Fail()
+	}
+	if d.Uid == 0 || d.Walker.Uid == 0 {
+		t.Fail()
+	}
+	// Adding again reuses the nodes instead of creating duplicates
+	uid := d.Walker.Uid
+	err = dg.Add(d)
+	if err != nil || d.Walker.Uid != uid {
+		t.Fail()
+	}
+	f := &Dog12{Uid: d.Uid}
+	err = dg.Find(f).Execute()
+	if err != nil || f.Name != "jarvis" || f.Walker == nil || f.Walker.Uid != uid {
+		t.Fail()
+	}
+	err = dg.Update(&Dog12{Name: "friday"})
+	if err != dgogm.ErrNoIdentity {
+		t.Fail()
+	}
+}
+
+type Toy struct {
+	Uid  uint64 `dgraph:"_uid_"`
+	Xid  string `dgraph:"_xid_"`
+	Name string `dgraph:"name"`
+}
+
+type Dog13 struct {
+	Uid  string `dgraph:"_uid_"`
+	Xid  string `dgraph:"_xid_"`
+	Name string `dgraph:"name"`
+	Toys []Toy  `dgraph:"toys"`
+}
+
+func TestDgraph_AddWritesBackIdentity(t *testing.T) {
+	dg, err := dgogm.Connect([]string{"127.0.0.1:9080"})
+	if err != nil {
+		t.Fail()
+	}
+	d := &Dog13{Name: "jarvis", Toys: []Toy{{Name: "ball"}, {Name: "bone"}}}
+	err = dg.Add(d)
+	if err != nil {
+		log.Println(err.Error())
+		t.Fail()
+	}
+	if d.Uid == "" || d.Xid == "" || d.Toys[0].Uid == 0 || d.Toys[1].Xid == "" {
+		t.Fail()
+	}
+	// Struct without uid field can be found again using the written back _xid_
+	f := &Dog13{Xid: d.Xid}
+	err = dg.Find(f).Execute()
+	if err != nil || f.Uid != d.Uid || f.Name != "jarvis" || len(f.Toys) != 2 || f.Toys[0].Xid == "" {
+		t.Fail()
+	}
+}
+
+func TestDgraph_DeleteEdge(t *testing.T) {
+	dg, err := dgogm.Connect([]string{"127.0.0.1:9080"})
+	if err != nil {
+		t.Fail()
+	}
+	d := new(Dog5)
+	d.Id = 1
+	err = dg.DeleteEdge(d, "LikesPlace", &Place{Id: 2})
+	if err != nil {
+		log.Println(err.Error())
+		t.Fail()
+	}
+}
+
+func TestDgraph_Delete(t *testing.T) {
+	dg, err := dgogm.Connect([]string{"127.0.0.1:9080"})
+	if err != nil {
+		t.Fail()
+	}
+	d := new(Dog1)
+	d.Id = 1
+	err = dg.Delete(d)
+	if err != nil {
+		log.Println(err.Error())
+		t.Fail()
+	}
+}
+
+func TestDgraph_DeleteCascade(t *testing.T) {
+	dg, err := dgogm.Connect([]string{"127.0.0.1:9080"})
+	if err != nil {
+		t.Fail()
+	}
+	d := new(Dog4)
+	d.Id = 1
+	d.LikesPlace = &Place{Id: 1}
+	err = dg.DeleteCascade(d)
+	if err != nil {
+		log.Println(err.Error())
+		t.Fail()
+	}
+}
+
+func TestDgraph_PlanMigration(t *testing.T) {
+	dg, err := dgogm.Connect([]string{"127.0.0.1:9080"})
+	if err != nil {
+		t.Fail()
+	}
+	plan, err := dg.PlanMigration(context.Background(), &Dog{})
+	if err != nil {
+		log.Println(err.Error())
+		t.Fail()
+	}
+	fmt.Println(plan)
+	err = dg.ApplyMigration(context.Background(), plan.WithoutDestructive())
+	if err != nil {
+		log.Println(err.Error())
+		t.Fail()
+	}
+}
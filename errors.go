@@ -0,0 +1,93 @@
+package dgogm
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+)
+
+var (
+	// ErrNotFound is returned when the queried node does not exist
+	ErrNotFound = errors.New("dgogm: node not found")
+	// ErrInvalidTarget is returned when the target is not a pointer to struct, or a pointer to slice of structs for FindAll
+	ErrInvalidTarget = errors.New("dgogm: invalid target")
+	// ErrNoIdentity is returned when the node of the struct is not known yet i.e. _uid_ is not set with ServerAssigned
+	ErrNoIdentity = errors.New("dgogm: identity of the node is unknown")
+	// ErrMultipleNodes is returned by Upsert when more than one node has the values of the key fields
+	ErrMultipleNodes = errors.New("dgogm: multiple nodes found")
+)
+
+// ErrUnsupportedType is returned when a field of the given type can not be stored in dgraph
+type ErrUnsupportedType struct {
+	Field string
+	Type  reflect.Type
+}
+
+func (e ErrUnsupportedType) Error() string {
+	if e.Field == "" {
+		return fmt.Sprintf("dgogm: %v is not supported", e.Type)
+	}
+	return fmt.Sprintf("dgogm: %v of field %s is not supported", e.Type, e.Field)
+}
+
+// ErrOverflow is returned when an unsigned value is too large for the int type of dgraph i.e. above math.MaxInt64
+type ErrOverflow struct {
+	Field string
+	Value uint64
+}
+
+func (e ErrOverflow) Error() string {
+	if e.Field == "" {
+		return fmt.Sprintf("dgogm: %d overflows int", e.Value)
+	}
+	return fmt.Sprintf("dgogm: %d of field %s overflows int", e.Value, e.Field)
+}
+
+// ErrRequired is returned when a field tagged as required i.e. dgraph:"email,required" has zero value
+type ErrRequired struct {
+	Field string
+}
+
+func (e ErrRequired) Error() string {
+	return fmt.Sprintf("dgogm: field %s is required", e.Field)
+}
+
+// ErrSchemaConflict is returned when the structs define the same predicate with different types or indexes
+type ErrSchemaConflict struct {
+	Predicate string
+	First     string
+	Second    string
+}
+
+func (e ErrSchemaConflict) Error() string {
+	return fmt.Sprintf("dgogm: predicate %s is defined as %q and %q", e.Predicate, e.First, e.Second)
+}
+
+// QueryError is returned when dgraph fails to execute the query
+type QueryError struct {
+	Query string
+	Cause error
+}
+
+func (e *QueryError) Error() string {
+	return fmt.Sprintf("dgogm: query %s failed: %v", e.Query, e.Cause)
+}
+
+func (e *QueryError) Unwrap() error {
+	return e.Cause
+}
+
+// MutationError is returned when dgraph fails to apply the mutation
+// Xid is the _xid_ of the node being mutated
+type MutationError struct {
+	Xid   string
+	Cause error
+}
+
+func (e *MutationError) Error() string {
+	return fmt.Sprintf("dgogm: mutation of %s failed: %v", e.Xid, e.Cause)
+}
+
+func (e *MutationError) Unwrap() error {
+	return e.Cause
+}
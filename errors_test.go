@@ -0,0 +1,40 @@
+package dgogm_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/akshaydeo/dgogm"
+)
+
+func TestInvalidTarget(t *testing.T) {
+	_, err := dgogm.Find(nil, Dog1{}).Query()
+	if !errors.Is(err, dgogm.ErrInvalidTarget) {
+		t.Fatal(err)
+	}
+	_, err = dgogm.FindAll(nil, &Dog1{}).Where(dgogm.Has("name")).Query()
+	if !errors.Is(err, dgogm.ErrInvalidTarget) {
+		t.Fatal(err)
+	}
+	err = dgogm.Add(nil, Dog1{})
+	if !errors.Is(err, dgogm.ErrInvalidTarget) {
+		t.Fatal(err)
+	}
+}
+
+func TestQueryError(t *testing.T) {
+	cause := errors.New("connection refused")
+	var err error = &dgogm.QueryError{Query: "{q(func: uid(0x1)){_uid_}}", Cause: cause}
+	var qe *dgogm.QueryError
+	if !errors.As(err, &qe) || !errors.Is(err, cause) {
+		t.Fatal(err)
+	}
+}
+
+func TestUnsupportedType(t *testing.T) {
+	_, err := dgogm.FindAll(nil, &[]Dog1{}).Where(dgogm.Has("name")).Max("name")
+	var ut dgogm.ErrUnsupportedType
+	if !errors.As(err, &ut) || ut.Field != "Name" {
+		t.Fatal(err)
+	}
+}
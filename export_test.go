@@ -0,0 +1,200 @@
+package dgogm
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/dgraph-io/dgraph/client"
+	"github.com/dgraph-io/dgraph/protos"
+	"google.golang.org/grpc/credentials"
+)
+
+// This function fills the given pointer to struct from the node, exported for the tests
+func ParseNodeTo(n *protos.Node, p interface{}) {
+	parseNodeTo(getLogger(), n, p)
+}
+
+// This function diffs the given live schema against the schema of the given structs, exported for the tests
+func DiffSchema(live []*protos.SchemaNode, types ...interface{}) (*MigrationPlan, error) {
+	predicates, err := getSchema(types...)
+	if err != nil {
+		return nil, err
+	}
+	return diffSchema(live, predicates), nil
+}
+
+// This function returns the mutation fired by Add for the given struct, exported for the tests
+func AddMutation(p interface{}) (string, error) {
+	r, w, _, err := addRequest(getLogger(), nil, nil, p, modeAdd)
+	if err != nil {
+		return "", err
+	}
+	return mutation(r, w), nil
+}
+
+// This function returns the mutations fired by Add for the given struct when dgraph assigns the given uids to the blank
+// nodes, the second one writes the maps of the new nodes, exported for the tests
+func AddMutations(p interface{}, uids ...uint64) (string, string, error) {
+	r, w, _, err := addRequest(getLogger(), nil, nil, p, modeAdd)
+	if err != nil {
+		return "", "", err
+	}
+	resp := &protos.Response{AssignedUids: map[string]uint64{}}
+	for _, n := range w.nodes {
+		if n.blank != "" && len(uids) > 0 {
+			resp.AssignedUids[n.blank], uids = uids[0], uids[1:]
+		}
+	}
+	m := mutation(r, w)
+	w.assigned(resp)
+	mr, err := w.mapRequest(getLogger(), nil)
+	if err != nil || mr == nil {
+		return m, "", err
+	}
+	return m, mutation(mr, newWrite(modeAdd, nil)), nil
+}
+
+// This function returns the mutation fired by Update for the given struct, exported for the tests
+func UpdateMutation(p interface{}, fields ...string) (string, error) {
+	r, w, _, err := updateRequest(getLogger(), nil, nil, p, fields)
+	if err != nil {
+		return "", err
+	}
+	return mutation(r, w), nil
+}
+
+// This function returns the mutation fired by Patch for the given struct, exported for the tests
+// Values are set into the struct as it's done after the request succeeds
+func PatchMutation(p interface{}, values map[string]interface{}) (string, error) {
+	r, w, _, err := patchRequest(getLogger(), nil, nil, p, values)
+	if err != nil {
+		return "", err
+	}
+	w.patch()
+	return mutation(r, w), nil
+}
+
+// This function returns the query used by Upsert for looking up the node, exported for the tests
+func UpsertQuery(p interface{}, keyFields ...string) (string, error) {
+	return upsertQuery(nil, getLogger(), p, keyFields)
+}
+
+// This function returns the mutation fired by Upsert when the lookup returns the given nodes, exported for the tests
+func UpsertMutation(p interface{}, nodes []*protos.Node, keyFields ...string) (string, error) {
+	r, w, _, err := upsertRequest(getLogger(), nil, nil, p, nodes, keyFields)
+	if err != nil {
+		return "", err
+	}
+	return mutation(r, w), nil
+}
+
+// This function returns the mutation fired by Delete, or DeleteCascade if cascade is true, exported for the tests
+func DeleteMutation(p interface{}, cascade bool) (string, error) {
+	r, err := deleteRequest(getLogger(), nil, nil, p, cascade)
+	if err != nil {
+		return "", err
+	}
+	return mutation(r, newWrite(modeAdd, nil)), nil
+}
+
+// This function returns the mutation fired by DeleteEdge, exported for the tests
+func DeleteEdgeMutation(p interface{}, edge string, target interface{}) (string, error) {
+	r, _, err := deleteEdgeRequest(getLogger(), nil, nil, p, edge, target)
+	if err != nil {
+		return "", err
+	}
+	return mutation(r, newWrite(modeAdd, nil)), nil
+}
+
+// This function writes the identities of the given structs back as it's done after a mutation returning resp
+// It returns the names of the blank nodes, exported for the tests
+func WriteBack(ids IdentityStrategy, resp *protos.Response, ps ...interface{}) ([]string, error) {
+	w := newWrite(modeAdd, ids)
+	names := []string{}
+	for _, p := range ps {
+		n, err := w.track(p)
+		if err != nil {
+			return nil, err
+		}
+		names = append(names, n.blank)
+	}
+	w.assigned(resp)
+	return names, nil
+}
+
+// This function replaces the client firing the requests until the returned function is called, exported for the tests
+func StubRun(run func(ctx context.Context, r *client.Req) (*protos.Response, error)) func() {
+	prev := runRequest
+	runRequest = func(c *client.Dgraph, ctx context.Context, r *client.Req) (*protos.Response, error) {
+		return run(ctx, r)
+	}
+	return func() { runRequest = prev }
+}
+
+// ConnectOptions holds the options applied by Connect, exported for the tests
+type ConnectOptions struct {
+	TLS           bool
+	DialTimeout   time.Duration
+	ClientDir     string
+	TempDir       bool
+	ClientOptions client.BatchMutationOptions
+	// Number of the grpc dial options
+	DialOptions int
+}
+
+// This function returns the options applied by Connect for the given ones, exported for the tests
+func ApplyOptions(opts ...Option) ConnectOptions {
+	o, tempDir := connectOptions(opts...)
+	return ConnectOptions{
+		TLS:           o.tls != nil,
+		DialTimeout:   o.dialTimeout,
+		ClientDir:     o.clientDir,
+		TempDir:       tempDir,
+		ClientOptions: o.clientOptions,
+		DialOptions:   len(o.grpcDialOptions()),
+	}
+}
+
+// This function returns the credentials added by WithAuthMetadata, exported for the tests
+func AuthMetadata(metadata map[string]string) credentials.PerRPCCredentials {
+	return authMetadata(metadata)
+}
+
+// This function converts the mutations to lines of nquads, cleared ones are fired before the request
+func mutation(r *client.Req, w *write) string {
+	lines := []string{}
+	if w.clears != nil {
+		for _, nq := range w.clears.Request().Mutation.Del {
+			lines = append(lines, fmt.Sprintf("clear %s", nquad(nq)))
+		}
+	}
+	m := r.Request().Mutation
+	if m == nil {
+		return strings.Join(lines, "\n")
+	}
+	for _, nq := range m.Del {
+		lines = append(lines, fmt.Sprintf("delete %s", nquad(nq)))
+	}
+	for _, nq := range m.Set {
+		lines = append(lines, fmt.Sprintf("set %s", nquad(nq)))
+	}
+	return strings.Join(lines, "\n")
+}
+
+// This function converts the nquad to its rdf representation, * is used for the deleted values
+func nquad(nq *protos.NQuad) string {
+	predicate := nq.Predicate
+	if predicate == "_STAR_ALL" {
+		predicate = "*"
+	}
+	if nq.ObjectId != "" {
+		return fmt.Sprintf("<%s> <%s> <%s> .", nq.Subject, predicate, nq.ObjectId)
+	}
+	if nq.ObjectValue.GetDefaultVal() == "_STAR_ALL" {
+		return fmt.Sprintf("<%s> <%s> * .", nq.Subject, predicate)
+	}
+	val, _ := convert(nq.ObjectValue)
+	return fmt.Sprintf("<%s> <%s> %q .", nq.Subject, predicate, fmt.Sprint(val))
+}
@@ -3,6 +3,7 @@ package dgogm
 import (
 	"fmt"
 	"reflect"
+	"strings"
 )
 
 // Field map type defines query fields
@@ -19,29 +20,53 @@ func (fm FieldMap) Add(key string, val interface{}) {
 	return
 }
 
+// This function checks if the given value is added under the key
+func (fm FieldMap) has(key string, val string) bool {
+	for _, e := range fm[key] {
+		if e == val {
+			return true
+		}
+	}
+	return false
+}
+
 // This function converts fieldmap to query string
 func (fm FieldMap) String() string {
+	return fm.withArgs(nil)
+}
+
+// This function converts fieldmap to query string
+// args are added to the edges, keyed by dotted path of the edge i.e. likes_places => first: 2
+func (fm FieldMap) withArgs(args map[string]string) string {
 	str := "_xid_ _uid_"
-	getQuery(&str, fm)
+	getQuery(&str, fm, "", args)
 	return str
 }
 
 // This function recursively creates query params for given field-map
-func getQuery(q *string, fm FieldMap) {
+func getQuery(q *string, fm FieldMap, path string, args map[string]string) {
 	for k, v := range fm {
+		cpath := path
 		if k != "" {
-			*q = fmt.Sprintf("%s %s { _xid_ _uid_", *q, k)
+			if cpath != "" {
+				cpath = fmt.Sprintf("%s.%s", cpath, k)
+			} else {
+				cpath = k
+			}
+			if args[cpath] != "" {
+				*q = fmt.Sprintf("%s %s (%s) { _xid_ _uid_", *q, k, args[cpath])
+			} else {
+				*q = fmt.Sprintf("%s %s { _xid_ _uid_", *q, k)
+			}
 		}
 		for _, e := range v {
 			switch e.(type) {
 			case FieldMap:
-				Debug("%s : Field map", k)
-				getQuery(q, e.(FieldMap))
+				getQuery(q, e.(FieldMap), cpath, args)
 			case string:
 				if e.(string) == "-" {
 					continue
 				}
-				Debug("%s: %s", k, e.(string))
 				*q = fmt.Sprintf("%s %s", *q, e.(string))
 			}
 		}
@@ -53,39 +78,135 @@ func getQuery(q *string, fm FieldMap) {
 
 // This function converts types into fields query for Dgraph
 func getFieldMap(t reflect.Type, parent string, m FieldMap) {
-	Debug("%s", t.Name())
-	for i := 0; i < t.NumField(); i++ {
-		Debug("Checking if its a primitive type %s", getFieldName(t.Field(i)))
-		if isPrimitiveType(t.Field(i).Type) {
-			m.Add(parent, getFieldName(t.Field(i)))
+	for _, f := range nodeFields(t) {
+		// _uid_ and _xid_ are always queried
+		if name := getFieldName(f); name == "_uid_" || name == "_xid_" {
+			continue
+		}
+		if isPrimitiveType(f.Type) {
+			m.Add(parent, getFieldName(f))
 			continue
 		}
-		Debug("Non primitive type %s", getFieldName(t.Field(i)))
-		switch t.Field(i).Type.Kind() {
+		switch f.Type.Kind() {
 		case reflect.Slice:
-			Debug("It's a slice")
-			Debug("%s []%s", getFieldName(t.Field(i)), t.Field(i).Type.Elem().Kind())
 			nm := FieldMap{}
-			if isPrimitiveType(t.Field(i).Type.Elem()) {
-				m.Add(parent, getFieldName(t.Field(i)))
+			if isPrimitiveType(f.Type.Elem()) {
+				m.Add(parent, getFieldName(f))
 				continue
 			}
-			switch t.Field(i).Type.Elem().Kind() {
+			switch f.Type.Elem().Kind() {
 			case reflect.Struct:
-				getFieldMap(t.Field(i).Type.Elem(), getFieldName(t.Field(i)), nm)
+				getFieldMap(f.Type.Elem(), getFieldName(f), nm)
 			case reflect.Ptr:
-				getFieldMap(t.Field(i).Type.Elem().Elem(), getFieldName(t.Field(i)), nm)
+				getFieldMap(f.Type.Elem().Elem(), getFieldName(f), nm)
 			}
 			m.Add(parent, nm)
 		case reflect.Struct:
 			nm := FieldMap{}
-			getFieldMap(t.Field(i).Type, getFieldName(t.Field(i)), nm)
+			getFieldMap(f.Type, getFieldName(f), nm)
 			m.Add(parent, nm)
 		case reflect.Ptr:
-			Debug("It's a ptr type")
 			nm := FieldMap{}
-			getFieldMap(t.Field(i).Type.Elem(), getFieldName(t.Field(i)), nm)
+			getFieldMap(f.Type.Elem(), getFieldName(f), nm)
 			m.Add(parent, nm)
+		case reflect.Map:
+			addMapField(f, parent, m)
+		}
+	}
+}
+
+// This function adds the map field to the fields query
+// Maps stored as json are plain predicates, otherwise all the predicates of the map node are expanded
+func addMapField(f reflect.StructField, parent string, m FieldMap) {
+	if _, ok := getFieldOptions(f)["json"]; ok {
+		m.Add(parent, getFieldName(f))
+		return
+	}
+	m.Add(parent, FieldMap{getFieldName(f): []interface{}{"expand(_all_)"}})
+}
+
+// This function returns the nested field map stored under parent for the given key
+// If there is no such field map, a new one is created and added to the parent
+func (fm FieldMap) child(parent, key string) FieldMap {
+	for _, e := range fm[parent] {
+		if nm, ok := e.(FieldMap); ok && nm[key] != nil {
+			return nm
+		}
+	}
+	nm := FieldMap{key: []interface{}{}}
+	fm.Add(parent, nm)
+	return nm
+}
+
+// This function returns the struct field of t matching the given name
+// Name can either be the go field name or the name returned by getFieldName
+func lookupField(t reflect.Type, name string) (reflect.StructField, bool) {
+	for _, f := range nodeFields(t) {
+		fname := getFieldName(f)
+		if fname == "-" {
+			continue
+		}
+		if fname == name || f.Name == name {
+			return f, true
+		}
+	}
+	return reflect.StructField{}, false
+}
+
+// This function returns the struct type behind a struct, pointer to struct or slice of those
+func nodeType(t reflect.Type) (reflect.Type, bool) {
+	if isScalarType(t) {
+		return nil, false
+	}
+	switch t.Kind() {
+	case reflect.Slice, reflect.Ptr:
+		return nodeType(t.Elem())
+	case reflect.Struct:
+		return t, true
+	}
+	return nil, false
+}
+
+// This function converts the given dotted field paths into fields query for Dgraph
+// i.e. name, likes_places.name will only select name of the node and name of each liked place
+func getProjectedFieldMap(t reflect.Type, paths []string, m FieldMap) error {
+	for _, path := range paths {
+		ct := t
+		cm := m
+		parent := ""
+		segments := strings.Split(path, ".")
+		for i, segment := range segments {
+			f, ok := lookupField(ct, segment)
+			if !ok {
+				return fmt.Errorf("%s does not have field %s", ct.Name(), path)
+			}
+			fname := getFieldName(f)
+			if f.Type.Kind() == reflect.Map {
+				if i != len(segments)-1 {
+					return fmt.Errorf("%s is not a relation in %s", fname, path)
+				}
+				addMapField(f, parent, cm)
+				break
+			}
+			if isPrimitiveType(f.Type) || (f.Type.Kind() == reflect.Slice && isPrimitiveType(f.Type.Elem())) {
+				if i != len(segments)-1 {
+					return fmt.Errorf("%s is not a relation in %s", fname, path)
+				}
+				cm.Add(parent, fname)
+				break
+			}
+			nt, ok := nodeType(f.Type)
+			if !ok {
+				return fmt.Errorf("%s is not supported in %s", f.Type.String(), path)
+			}
+			cm = cm.child(parent, fname)
+			parent = fname
+			ct = nt
+			if i == len(segments)-1 {
+				// Selecting the whole relation
+				getFieldMap(nt, fname, cm)
+			}
 		}
 	}
+	return nil
 }
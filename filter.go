@@ -0,0 +1,207 @@
+package dgogm
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Filter defines a dgraph function, used either at the root of the query or in @filter
+// Field names are resolved through the struct tags, so both go field names and dgraph names can be used
+type Filter interface {
+	// build returns dgraph representation of the filter for the given struct type
+	build(t reflect.Type) (string, error)
+}
+
+// This struct defines a single dgraph function like eq(name, "jarvis")
+type funcFilter struct {
+	name  string
+	field string
+	args  []string
+}
+
+// This struct defines and/or combination of filters
+type logicalFilter struct {
+	op      string
+	filters []Filter
+}
+
+// This struct defines negation of a filter
+type notFilter struct {
+	filter Filter
+}
+
+// This function creates eq(field, value) filter
+func Eq(field string, value interface{}) Filter {
+	return &funcFilter{name: "eq", field: field, args: []string{literal(value)}}
+}
+
+// This function creates lt(field, value) filter
+func Lt(field string, value interface{}) Filter {
+	return &funcFilter{name: "lt", field: field, args: []string{literal(value)}}
+}
+
+// This function creates le(field, value) filter
+func Le(field string, value interface{}) Filter {
+	return &funcFilter{name: "le", field: field, args: []string{literal(value)}}
+}
+
+// This function creates gt(field, value) filter
+func Gt(field string, value interface{}) Filter {
+	return &funcFilter{name: "gt", field: field, args: []string{literal(value)}}
+}
+
+// This function creates ge(field, value) filter
+func Ge(field string, value interface{}) Filter {
+	return &funcFilter{name: "ge", field: field, args: []string{literal(value)}}
+}
+
+// This function creates anyofterms(field, terms) filter
+func AnyOfTerms(field string, terms string) Filter {
+	return &funcFilter{name: "anyofterms", field: field, args: []string{literal(terms)}}
+}
+
+// This function creates allofterms(field, terms) filter
+func AllOfTerms(field string, terms string) Filter {
+	return &funcFilter{name: "allofterms", field: field, args: []string{literal(terms)}}
+}
+
+// This function creates anyoftext(field, text) filter
+func AnyOfText(field string, text string) Filter {
+	return &funcFilter{name: "anyoftext", field: field, args: []string{literal(text)}}
+}
+
+// This function creates alloftext(field, text) filter
+func AllOfText(field string, text string) Filter {
+	return &funcFilter{name: "alloftext", field: field, args: []string{literal(text)}}
+}
+
+// This function creates regexp(field, /pattern/) filter, slashes in the pattern are escaped
+func Regexp(field string, pattern string) Filter {
+	return &funcFilter{name: "regexp", field: field, args: []string{fmt.Sprintf("/%s/", strings.Replace(pattern, "/", "\\/", -1))}}
+}
+
+// This function creates has(field) filter
+func Has(field string) Filter {
+	return &funcFilter{name: "has", field: field}
+}
+
+// This function combines the given filters with AND
+func And(filters ...Filter) Filter {
+	return &logicalFilter{op: "AND", filters: filters}
+}
+
+// This function combines the given filters with OR
+func Or(filters ...Filter) Filter {
+	return &logicalFilter{op: "OR", filters: filters}
+}
+
+// This function negates the given filter
+func Not(filter Filter) Filter {
+	return &notFilter{filter: filter}
+}
+
+func (f *funcFilter) build(t reflect.Type) (string, error) {
+	field, ok := lookupField(t, f.field)
+	if !ok {
+		return "", fmt.Errorf("%s does not have field %s", t.Name(), f.field)
+	}
+	return fmt.Sprintf("%s(%s)", f.name, strings.Join(append([]string{getFieldName(field)}, f.args...), ", ")), nil
+}
+
+func (f *logicalFilter) build(t reflect.Type) (string, error) {
+	if len(f.filters) == 0 {
+		return "", fmt.Errorf("%s requires at least one filter", f.op)
+	}
+	parts := make([]string, 0, len(f.filters))
+	for _, filter := range f.filters {
+		part, err := filter.build(t)
+		if err != nil {
+			return "", err
+		}
+		parts = append(parts, part)
+	}
+	return fmt.Sprintf("(%s)", strings.Join(parts, fmt.Sprintf(" %s ", f.op))), nil
+}
+
+func (f *notFilter) build(t reflect.Type) (string, error) {
+	part, err := f.filter.build(t)
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("NOT %s", part), nil
+}
+
+// This function splits the given filter into the function used at the root of the query
+// and the @filter directive
+// Dgraph only accepts a single function at the root, so for the AND combination first function
+// is used at the root and for OR/NOT the nodes of the struct type are filtered, see typeRoot
+func rootAndFilter(f Filter, t reflect.Type) (string, string, error) {
+	filter, err := f.build(t)
+	if err != nil {
+		return "", "", err
+	}
+	switch f.(type) {
+	case *funcFilter:
+		return filter, "", nil
+	case *logicalFilter:
+		if f.(*logicalFilter).op == "AND" {
+			for _, c := range f.(*logicalFilter).filters {
+				if _, ok := c.(*funcFilter); ok {
+					root, _ := c.build(t)
+					return root, fmt.Sprintf(" @filter(%s)", filter), nil
+				}
+			}
+		}
+	}
+	root, err := typeRoot(t)
+	if err != nil {
+		return "", "", err
+	}
+	return root, fmt.Sprintf(" @filter(%s)", filter), nil
+}
+
+// This function returns the root function matching all the nodes of the given struct type
+// Dgraph does not have types, so the nodes having the first required field are matched as every written node has it
+// Other fields can be missing, so the structs without required fields fail instead of silently missing the nodes
+func typeRoot(t reflect.Type) (string, error) {
+	for _, f := range nodeFields(t) {
+		name := getFieldName(f)
+		if name == "-" || isCountField(name) || name == "_uid_" || name == "_xid_" {
+			continue
+		}
+		opts := getFieldOptions(f)
+		if _, ok := opts["readonly"]; ok {
+			continue
+		}
+		if _, ok := opts["required"]; ok {
+			return fmt.Sprintf("has(%s)", name), nil
+		}
+	}
+	return "", fmt.Errorf("%s does not have a required field, Or and Not can not be used at the root of its query", t.Name())
+}
+
+// This function converts the given value to the dgraph literal
+// Pointers, Null and custom types are converted into the values stored in dgraph first, so named strings are quoted too
+func literal(value interface{}) string {
+	val, err := marshalValue(reflect.ValueOf(value))
+	if err != nil || value == nil {
+		return fmt.Sprintf("%v", value)
+	}
+	switch val.(type) {
+	case nil:
+		// Empty strings are not stored, but they can still be compared
+		return strconv.Quote("")
+	case string:
+		return strconv.Quote(val.(string))
+	case []byte:
+		return strconv.Quote(string(val.([]byte)))
+	case time.Time:
+		return strconv.Quote(val.(time.Time).Format(time.RFC3339))
+	case bool:
+		return strconv.FormatBool(val.(bool))
+	}
+	return fmt.Sprintf("%v", val)
+}
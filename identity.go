@@ -0,0 +1,227 @@
+package dgogm
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// IdentityStrategy decides the node of the structs stored in dgraph
+// Identity returns the _xid_ and the uid of the node of the given pointer to struct
+// Uid 0 means the node is not known yet, such nodes are created as blank nodes and dgraph assigns their uids
+type IdentityStrategy interface {
+	Identity(p interface{}) (xid string, uid uint64, err error)
+}
+
+var (
+	// HashedXid uses GetUId as _xid_ and its 64-bit FNV-1a hash as uid, this is the default strategy
+	// uid held by the field tagged dgraph:"_uid_", or else _xid_ held by the field tagged dgraph:"_xid_",
+	// is used only instead of the random uuid generated by GetUId
+	HashedXid IdentityStrategy = hashedXid{}
+	// ServerAssigned uses the uid in the field tagged dgraph:"_uid_", nodes without it are created as blank nodes
+	// and the uids assigned by dgraph are written back into that field. Nodes without uid are queried by _xid_
+	ServerAssigned IdentityStrategy = serverAssigned{}
+	// CompositeKey uses the values of the fields tagged with key option i.e. dgraph:"email,key" as _xid_ and its hash as uid
+	CompositeKey IdentityStrategy = compositeKey{}
+	// CallerSupplied uses the uid in the field tagged dgraph:"_uid_", which is required
+	CallerSupplied IdentityStrategy = callerSupplied{}
+)
+
+var (
+	identityMu         sync.RWMutex
+	identityStrategies = map[reflect.Type]IdentityStrategy{}
+)
+
+// This function sets the identity strategy used for the type of the given struct or pointer to struct
+// It takes precedence over the strategy of the Dgraph handle, passing nil removes it
+func RegisterIdentityStrategy(s interface{}, strategy IdentityStrategy) {
+	t := reflect.TypeOf(s)
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	identityMu.Lock()
+	defer identityMu.Unlock()
+	if strategy == nil {
+		delete(identityStrategies, t)
+		return
+	}
+	identityStrategies[t] = strategy
+}
+
+// This function sets the identity strategy used by this handle, HashedXid is used if it's nil
+// Strategies registered for the types using RegisterIdentityStrategy take precedence
+func (d *Dgraph) SetIdentityStrategy(strategy IdentityStrategy) {
+	d.ids = strategy
+}
+
+// This function returns the _xid_ and uid of the node of p using the strategy registered for its type,
+// falling back to the given strategy and then to HashedXid
+func identity(ids IdentityStrategy, p interface{}) (string, uint64, error) {
+	identityMu.RLock()
+	strategy, ok := identityStrategies[reflect.TypeOf(p).Elem()]
+	identityMu.RUnlock()
+	if !ok {
+		strategy = ids
+	}
+	if strategy == nil {
+		strategy = HashedXid
+	}
+	return strategy.Identity(p)
+}
+
+// This function returns the uid of the node of p, ErrNoIdentity is returned if the node is not known
+func knownIdentity(ids IdentityStrategy, p interface{}) (string, uint64, error) {
+	xid, uid, err := identity(ids, p)
+	if err != nil {
+		return "", 0, err
+	}
+	if uid == 0 {
+		return "", 0, ErrNoIdentity
+	}
+	return xid, uid, nil
+}
+
+type hashedXid struct{}
+
+func (hashedXid) Identity(p interface{}) (string, uint64, error) {
+	xid, ok := providedUId(p)
+	if ok {
+		return xid, hash(xid), nil
+	}
+	// uid and _xid_ written back after adding are kept, so that the random ones given by GetUId find the same node
+	if uid, _ := getUid(p); uid != 0 {
+		return uidXid(uid, p), uid, nil
+	}
+	if xid = storedXid(p); xid == "" {
+		xid = GetUId(p)
+	}
+	return xid, hash(xid), nil
+}
+
+type serverAssigned struct{}
+
+func (serverAssigned) Identity(p interface{}) (string, uint64, error) {
+	uid, _ := getUid(p)
+	if uid != 0 {
+		return uidXid(uid, p), uid, nil
+	}
+	if xid := storedXid(p); xid != "" {
+		return xid, 0, nil
+	}
+	return GetUId(p), 0, nil
+}
+
+type compositeKey struct{}
+
+func (compositeKey) Identity(p interface{}) (string, uint64, error) {
+	v := reflect.ValueOf(p).Elem()
+	var keys []string
+	for _, f := range nodeFields(v.Type()) {
+		if _, ok := getFieldOptions(f)["key"]; !ok {
+			continue
+		}
+		fv, ok := fieldValue(v, f.Index, false)
+		if !ok || IsZero(fv) {
+			return "", 0, ErrRequired{Field: f.Name}
+		}
+		// Keys are prefixed with their length, so that the values containing the separator do not collide
+		key := fmt.Sprint(reflect.Indirect(fv).Interface())
+		keys = append(keys, fmt.Sprintf("%d:%s", len(key), key))
+	}
+	if len(keys) == 0 {
+		return "", 0, fmt.Errorf("%s does not have key fields", v.Type().Name())
+	}
+	xid := typedXid(strings.Join(keys, "_"), p)
+	return xid, hash(xid), nil
+}
+
+type callerSupplied struct{}
+
+func (callerSupplied) Identity(p interface{}) (string, uint64, error) {
+	uid, f := getUid(p)
+	if uid == 0 {
+		return "", 0, ErrRequired{Field: f}
+	}
+	return uidXid(uid, p), uid, nil
+}
+
+// This function returns the _xid_ for the given id of p i.e. 1_dog
+func typedXid(id string, p interface{}) string {
+	return fmt.Sprintf("%s_%s", id, strings.ToLower(reflect.TypeOf(p).Elem().Name()))
+}
+
+// This function returns the _xid_ of the node with the given uid
+// _xid_ held by the field of p tagged dgraph:"_xid_" is kept, otherwise the uid is used i.e. 26_dog
+func uidXid(uid uint64, p interface{}) string {
+	if xid := storedXid(p); xid != "" {
+		return xid
+	}
+	return typedXid(strconv.FormatUint(uid, 10), p)
+}
+
+// This function returns the _xid_ held by the field of p tagged dgraph:"_xid_", empty if there is none
+func storedXid(p interface{}) string {
+	_, fv, ok := tagField(p, "_xid_")
+	if !ok || fv.Kind() != reflect.String {
+		return ""
+	}
+	return fv.String()
+}
+
+// This function stores the given _xid_ into the field of p tagged dgraph:"_xid_"
+func storeXid(p interface{}, xid string) {
+	_, fv, ok := tagField(p, "_xid_")
+	if !ok || !fv.CanSet() || fv.Kind() != reflect.String {
+		return
+	}
+	fv.SetString(xid)
+}
+
+// This function returns the field of p with the given dgraph name
+func tagField(p interface{}, name string) (reflect.StructField, reflect.Value, bool) {
+	v := reflect.ValueOf(p).Elem()
+	for _, f := range nodeFields(v.Type()) {
+		if getFieldName(f) != name {
+			continue
+		}
+		fv, ok := fieldValue(v, f.Index, false)
+		return f, fv, ok
+	}
+	return reflect.StructField{}, reflect.Value{}, false
+}
+
+// This function returns the uid held by the field of p tagged dgraph:"_uid_", along with the name of the field
+// Uids can be held in unsigned integers, or strings in hex (0x1a) or decimal
+func getUid(p interface{}) (uint64, string) {
+	f, fv, ok := tagField(p, "_uid_")
+	if !ok {
+		return 0, "_uid_"
+	}
+	switch fv.Kind() {
+	case reflect.Uint, reflect.Uint64:
+		return fv.Uint(), f.Name
+	case reflect.String:
+		uid, err := strconv.ParseUint(fv.String(), 0, 64)
+		if err != nil {
+			return 0, f.Name
+		}
+		return uid, f.Name
+	}
+	return 0, f.Name
+}
+
+// This function sets the given uid into the field of p tagged dgraph:"_uid_", strings are set in hex i.e. 0x1a
+func setUid(p interface{}, uid uint64) {
+	_, fv, ok := tagField(p, "_uid_")
+	if !ok || !fv.CanSet() {
+		return
+	}
+	switch fv.Kind() {
+	case reflect.Uint, reflect.Uint64:
+		fv.SetUint(uid)
+	case reflect.String:
+		fv.SetString(fmt.Sprintf("%#x", uid))
+	}
+}
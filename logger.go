@@ -1,11 +1,127 @@
 package dgogm
 
-import "fmt"
+import (
+	"fmt"
+	"log"
+	"log/slog"
+	"strings"
+	"sync"
+)
 
+// Logger is used by dgogm for all of its logging
+// Key values are passed as alternating keys and values i.e. Debug("Firing query", "query", q)
+// *slog.Logger satisfies this interface
+type Logger interface {
+	Debug(msg string, keyvals ...interface{})
+	Info(msg string, keyvals ...interface{})
+	Warn(msg string, keyvals ...interface{})
+	Error(msg string, keyvals ...interface{})
+}
+
+var (
+	loggerMu      sync.RWMutex
+	defaultLogger Logger = nopLogger{}
+)
+
+// This function sets the logger used by dgogm, passing nil makes dgogm silent again
+// Handles with their own logger set using Dgraph.SetLogger keep using that
+func SetLogger(l Logger) {
+	if l == nil {
+		l = nopLogger{}
+	}
+	loggerMu.Lock()
+	defaultLogger = l
+	loggerMu.Unlock()
+}
+
+// This function returns the global logger
+func getLogger() Logger {
+	loggerMu.RLock()
+	defer loggerMu.RUnlock()
+	return defaultLogger
+}
+
+// This function sets the logger used for the operations done using this handle
+// Passing nil makes the handle use the global logger
+func (d *Dgraph) SetLogger(l Logger) {
+	d.logger = l
+}
+
+// This function returns the logger of this handle, falling back to the global logger
+func (d *Dgraph) log() Logger {
+	if d.logger != nil {
+		return d.logger
+	}
+	return getLogger()
+}
+
+// This function returns the given logger, falling back to the global logger if it's nil
+func loggerOrDefault(l Logger) Logger {
+	if l != nil {
+		return l
+	}
+	return getLogger()
+}
+
+// This function returns the Logger writing to the given slog.Logger
+func SlogLogger(l *slog.Logger) Logger {
+	return l
+}
+
+// This function returns the Logger writing to the given log.Logger
+// Lines are written as LEVEL message key=value
+func StdLogger(l *log.Logger) Logger {
+	return &stdLogger{l: l}
+}
+
+// stdLogger writes to log.Logger
+type stdLogger struct {
+	l *log.Logger
+}
+
+func (sl *stdLogger) Debug(msg string, keyvals ...interface{}) {
+	sl.l.Print(format("DEBUG", msg, keyvals))
+}
+
+func (sl *stdLogger) Info(msg string, keyvals ...interface{}) {
+	sl.l.Print(format("INFO", msg, keyvals))
+}
+
+func (sl *stdLogger) Warn(msg string, keyvals ...interface{}) {
+	sl.l.Print(format("WARN", msg, keyvals))
+}
+
+func (sl *stdLogger) Error(msg string, keyvals ...interface{}) {
+	sl.l.Print(format("ERROR", msg, keyvals))
+}
+
+// This function formats the log line as LEVEL message key=value
+func format(level string, msg string, keyvals []interface{}) string {
+	parts := []string{level, msg}
+	for i := 0; i < len(keyvals); i += 2 {
+		if i+1 == len(keyvals) {
+			parts = append(parts, fmt.Sprintf("%v", keyvals[i]))
+			continue
+		}
+		parts = append(parts, fmt.Sprintf("%v=%v", keyvals[i], keyvals[i+1]))
+	}
+	return strings.Join(parts, " ")
+}
+
+// nopLogger discards everything, it's the default logger
+type nopLogger struct{}
+
+func (nopLogger) Debug(msg string, keyvals ...interface{}) {}
+func (nopLogger) Info(msg string, keyvals ...interface{})  {}
+func (nopLogger) Warn(msg string, keyvals ...interface{})  {}
+func (nopLogger) Error(msg string, keyvals ...interface{}) {}
+
+// This function logs the formatted message at debug level using the global logger
 func Debug(f string, p ...interface{}) {
-	fmt.Println(fmt.Sprintf(f, p...))
+	getLogger().Debug(fmt.Sprintf(f, p...))
 }
 
+// This function logs the formatted message at error level using the global logger
 func Error(f string, p ...interface{}) {
-	fmt.Println(fmt.Sprintf(f, p...))
+	getLogger().Error(fmt.Sprintf(f, p...))
 }
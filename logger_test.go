@@ -0,0 +1,29 @@
+package dgogm_test
+
+import (
+	"bytes"
+	"log"
+	"strings"
+	"testing"
+
+	"github.com/akshaydeo/dgogm"
+)
+
+func TestStdLogger(t *testing.T) {
+	buf := new(bytes.Buffer)
+	l := dgogm.StdLogger(log.New(buf, "", 0))
+	l.Debug("Firing query", "query", "{q(func: uid(0x1)){_uid_}}", "dangling")
+	if strings.TrimSpace(buf.String()) != "DEBUG Firing query query={q(func: uid(0x1)){_uid_}} dangling" {
+		t.Fatal(buf.String())
+	}
+}
+
+func TestSetLogger(t *testing.T) {
+	buf := new(bytes.Buffer)
+	dgogm.SetLogger(dgogm.StdLogger(log.New(buf, "", 0)))
+	defer dgogm.SetLogger(nil)
+	dgogm.Error("Error while %s", "testing")
+	if strings.TrimSpace(buf.String()) != "ERROR Error while testing" {
+		t.Fatal(buf.String())
+	}
+}
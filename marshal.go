@@ -0,0 +1,163 @@
+package dgogm
+
+import (
+	"encoding"
+	"math"
+	"reflect"
+	"time"
+)
+
+// ValueMarshaler is implemented by the types which store themselves as a single predicate
+// MarshalDgraph should return a string, bool, any int or float, time.Time, []byte or *GeoPoint
+type ValueMarshaler interface {
+	MarshalDgraph() (interface{}, error)
+}
+
+// ValueUnmarshaler is implemented by the types which load themselves from a single predicate
+// UnmarshalDgraph gets the value as returned by dgraph i.e. string, bool, int64, float64, time.Time, []byte or GeoPoint
+type ValueUnmarshaler interface {
+	UnmarshalDgraph(val interface{}) error
+}
+
+var (
+	valueMarshalerType   = reflect.TypeOf((*ValueMarshaler)(nil)).Elem()
+	valueUnmarshalerType = reflect.TypeOf((*ValueUnmarshaler)(nil)).Elem()
+	textMarshalerType    = reflect.TypeOf((*encoding.TextMarshaler)(nil)).Elem()
+	textUnmarshalerType  = reflect.TypeOf((*encoding.TextUnmarshaler)(nil)).Elem()
+)
+
+// This function returns if the given type or pointer to it implements the given interface
+func implements(tp reflect.Type, i reflect.Type) bool {
+	return tp.Implements(i) || (tp.Kind() != reflect.Ptr && reflect.PtrTo(tp).Implements(i))
+}
+
+// This function returns if given type marshals itself using ValueMarshaler or encoding.TextMarshaler
+func isMarshalerType(tp reflect.Type) bool {
+	return implements(tp, valueMarshalerType) || implements(tp, textMarshalerType)
+}
+
+// This function returns the interface implemented by the value, looking at its address as well
+// for the methods with pointer receivers
+func methodsOf(value reflect.Value, i reflect.Type) (interface{}, bool) {
+	if value.Type().Implements(i) {
+		if value.Kind() == reflect.Ptr && value.IsNil() {
+			return nil, false
+		}
+		return value.Interface(), true
+	}
+	if value.Kind() != reflect.Ptr && value.CanAddr() && value.Addr().Type().Implements(i) {
+		return value.Addr().Interface(), true
+	}
+	return nil, false
+}
+
+// This function converts the given scalar into the value stored in dgraph, nil is returned for nil pointers and zero values
+// ValueMarshaler takes precedence over the built in scalars, encoding.TextMarshaler is used as the fallback
+func marshalValue(value reflect.Value) (interface{}, error) {
+	for {
+		if m, ok := methodsOf(value, valueMarshalerType); ok {
+			val, err := m.(ValueMarshaler).MarshalDgraph()
+			if err != nil {
+				return nil, err
+			}
+			return normalizeValue(val), nil
+		}
+		if value.Kind() != reflect.Ptr {
+			break
+		}
+		if value.IsNil() {
+			return nil, nil
+		}
+		value = value.Elem()
+	}
+	if IsZero(value) && !isZeroWritable(value.Kind()) {
+		return nil, nil
+	}
+	switch value.Type() {
+	case timeType:
+		return value.Interface().(time.Time), nil
+	case geoPointType:
+		gp := value.Interface().(GeoPoint)
+		return &gp, nil
+	case rawMessageType:
+		return string(value.Bytes()), nil
+	case bytesType:
+		return value.Bytes(), nil
+	}
+	if m, ok := methodsOf(value, textMarshalerType); ok {
+		text, err := m.(encoding.TextMarshaler).MarshalText()
+		if err != nil {
+			return nil, err
+		}
+		return string(text), nil
+	}
+	// Types only implementing ValueUnmarshaler i.e. type Status int
+	if isNumber(value.Kind()) || value.Kind() == reflect.String || value.Kind() == reflect.Bool {
+		return normalizeValue(value.Interface()), nil
+	}
+	return nil, ErrUnsupportedType{Type: value.Type()}
+}
+
+// This function converts the values returned by ValueMarshaler into the types accepted by setVal
+func normalizeValue(val interface{}) interface{} {
+	switch val.(type) {
+	case nil, time.Time, []byte, *GeoPoint:
+		return val
+	case GeoPoint:
+		gp := val.(GeoPoint)
+		return &gp
+	}
+	v := reflect.ValueOf(val)
+	switch v.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return v.Int()
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		// Values above math.MaxInt64 are kept as uint64, setVal rejects them
+		if v.Uint() > math.MaxInt64 {
+			return v.Uint()
+		}
+		return int64(v.Uint())
+	case reflect.Float32, reflect.Float64:
+		return v.Float()
+	case reflect.String:
+		return v.String()
+	case reflect.Bool:
+		return v.Bool()
+	case reflect.Ptr:
+		if v.IsNil() {
+			return nil
+		}
+		return normalizeValue(v.Elem().Interface())
+	}
+	return val
+}
+
+// This function creates the value of the given type from the value returned by dgraph
+// using ValueUnmarshaler or encoding.TextUnmarshaler
+func unmarshalValue(val interface{}, t reflect.Type) (reflect.Value, bool) {
+	ptr := reflect.New(t)
+	if u, ok := ptr.Interface().(ValueUnmarshaler); ok {
+		if err := u.UnmarshalDgraph(val); err != nil {
+			getLogger().Warn("Unmarshalling failed", "type", t.String(), "value", val, "error", err)
+			return reflect.Value{}, false
+		}
+		return ptr.Elem(), true
+	}
+	if u, ok := ptr.Interface().(encoding.TextUnmarshaler); ok {
+		var text []byte
+		switch val.(type) {
+		case string:
+			text = []byte(val.(string))
+		case []byte:
+			text = val.([]byte)
+		default:
+			return reflect.Value{}, false
+		}
+		if err := u.UnmarshalText(text); err != nil {
+			getLogger().Warn("Unmarshalling failed", "type", t.String(), "value", val, "error", err)
+			return reflect.Value{}, false
+		}
+		return ptr.Elem(), true
+	}
+	return reflect.Value{}, false
+}
@@ -0,0 +1,214 @@
+package dgogm
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/dgraph-io/dgraph/client"
+	"github.com/dgraph-io/dgraph/protos"
+)
+
+// MigrationKind defines what a migration step changes
+type MigrationKind string
+
+const (
+	AddPredicate  MigrationKind = "add_predicate"
+	ChangeType    MigrationKind = "change_type"
+	AddIndex      MigrationKind = "add_index"
+	RemoveIndex   MigrationKind = "remove_index"
+	AddReverse    MigrationKind = "add_reverse"
+	RemoveReverse MigrationKind = "remove_reverse"
+	AddCount      MigrationKind = "add_count"
+	RemoveCount   MigrationKind = "remove_count"
+)
+
+// MigrationStep is a single change of a predicate between the live schema and the go types
+// From and To hold the live and the expected value i.e. types for ChangeType and tokenizers for AddIndex
+// Destructive steps can lose data or capabilities, i.e. changing type or removing an index
+type MigrationStep struct {
+	Kind        MigrationKind
+	Predicate   string
+	From        string
+	To          string
+	Destructive bool
+}
+
+func (ms MigrationStep) String() string {
+	str := fmt.Sprintf("%s %s", ms.Kind, ms.Predicate)
+	if ms.From != "" || ms.To != "" {
+		str = fmt.Sprintf("%s: %s => %s", str, ms.From, ms.To)
+	}
+	if ms.Destructive {
+		str = fmt.Sprintf("%s (destructive)", str)
+	}
+	return str
+}
+
+// MigrationPlan holds the steps needed for bringing the live schema in line with the go types
+// Predicates which are present only in the live schema are left untouched
+type MigrationPlan struct {
+	Steps      []MigrationStep
+	predicates map[string]*schemaPredicate
+}
+
+// This function returns if any of the steps is destructive
+func (mp *MigrationPlan) Destructive() bool {
+	for _, s := range mp.Steps {
+		if s.Destructive {
+			return true
+		}
+	}
+	return false
+}
+
+// This function returns a copy of the plan without the destructive steps
+// Predicates having a destructive step are dropped entirely, as schema is altered per predicate
+func (mp *MigrationPlan) WithoutDestructive() *MigrationPlan {
+	destructive := map[string]bool{}
+	for _, s := range mp.Steps {
+		if s.Destructive {
+			destructive[s.Predicate] = true
+		}
+	}
+	plan := &MigrationPlan{predicates: map[string]*schemaPredicate{}}
+	for _, s := range mp.Steps {
+		if destructive[s.Predicate] {
+			continue
+		}
+		plan.Steps = append(plan.Steps, s)
+		plan.predicates[s.Predicate] = mp.predicates[s.Predicate]
+	}
+	return plan
+}
+
+// This function returns the schema which is applied for this plan
+func (mp *MigrationPlan) Schema() string {
+	names := []string{}
+	for name := range mp.predicates {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	lines := []string{}
+	for _, name := range names {
+		lines = append(lines, mp.predicates[name].String())
+	}
+	return strings.Join(lines, "\n")
+}
+
+func (mp *MigrationPlan) String() string {
+	lines := []string{}
+	for _, s := range mp.Steps {
+		lines = append(lines, s.String())
+	}
+	return strings.Join(lines, "\n")
+}
+
+// This function reads the live schema and diffs it against the schema of the given structs
+func (d *Dgraph) PlanMigration(ctx context.Context, types ...interface{}) (*MigrationPlan, error) {
+	return planMigration(ctx, d.log(), d.client, types...)
+}
+
+// This function reads the live schema and diffs it against the schema of the given structs
+func PlanMigration(ctx context.Context, c *client.Dgraph, types ...interface{}) (*MigrationPlan, error) {
+	return planMigration(ctx, getLogger(), c, types...)
+}
+
+// This function applies the given plan to the dgraph
+// Use MigrationPlan.WithoutDestructive for skipping the destructive steps
+func (d *Dgraph) ApplyMigration(ctx context.Context, plan *MigrationPlan) error {
+	return applyMigration(ctx, d.log(), d.client, plan)
+}
+
+// This function applies the given plan to the dgraph
+// Use MigrationPlan.WithoutDestructive for skipping the destructive steps
+func ApplyMigration(ctx context.Context, c *client.Dgraph, plan *MigrationPlan) error {
+	return applyMigration(ctx, getLogger(), c, plan)
+}
+
+// Internal function, fetching the live schema and creating the plan
+func planMigration(ctx context.Context, l Logger, c *client.Dgraph, types ...interface{}) (*MigrationPlan, error) {
+	r := new(client.Req)
+	r.SetQuery(GET_SCHEMA)
+	resp, err := runRequest(c, ctx, r)
+	if err != nil {
+		l.Error("Query failed", "query", GET_SCHEMA, "error", err)
+		return nil, &QueryError{Query: GET_SCHEMA, Cause: err}
+	}
+	predicates, err := getSchema(types...)
+	if err != nil {
+		return nil, err
+	}
+	return diffSchema(resp.Schema, predicates), nil
+}
+
+// Internal function, altering the schema of the predicates in the plan
+func applyMigration(ctx context.Context, l Logger, c *client.Dgraph, plan *MigrationPlan) error {
+	if len(plan.predicates) == 0 {
+		return nil
+	}
+	return alterSchema(ctx, l, c, plan.Schema())
+}
+
+// This function diffs the live schema against the expected predicates
+func diffSchema(live []*protos.SchemaNode, expected []*schemaPredicate) *MigrationPlan {
+	current := map[string]*protos.SchemaNode{}
+	for _, sn := range live {
+		current[sn.Predicate] = sn
+	}
+	plan := &MigrationPlan{predicates: map[string]*schemaPredicate{}}
+	for _, sp := range expected {
+		steps := diffPredicate(current[sp.name], sp)
+		if len(steps) == 0 {
+			continue
+		}
+		plan.Steps = append(plan.Steps, steps...)
+		plan.predicates[sp.name] = sp
+	}
+	return plan
+}
+
+// This function returns the steps needed for changing the live predicate to the expected one
+func diffPredicate(sn *protos.SchemaNode, sp *schemaPredicate) []MigrationStep {
+	if sn == nil {
+		return []MigrationStep{{Kind: AddPredicate, Predicate: sp.name, To: sp.String()}}
+	}
+	steps := []MigrationStep{}
+	typ := sn.Type
+	if sn.List {
+		typ = fmt.Sprintf("[%s]", typ)
+	}
+	if typ != sp.typ {
+		// Predicates created by mutations before the schema was applied are typed default, typing them loses nothing
+		destructive := typ != "default"
+		steps = append(steps, MigrationStep{Kind: ChangeType, Predicate: sp.name, From: typ, To: sp.typ, Destructive: destructive})
+	}
+	liveIndex := map[string]bool{}
+	for _, t := range sn.Tokenizer {
+		liveIndex[t] = true
+	}
+	expectedIndex := map[string]bool{}
+	for _, t := range sp.index {
+		expectedIndex[t] = true
+		if !liveIndex[t] {
+			steps = append(steps, MigrationStep{Kind: AddIndex, Predicate: sp.name, To: t})
+		}
+	}
+	for _, t := range sn.Tokenizer {
+		if !expectedIndex[t] {
+			steps = append(steps, MigrationStep{Kind: RemoveIndex, Predicate: sp.name, From: t, Destructive: true})
+		}
+	}
+	if sp.reverse && !sn.Reverse {
+		steps = append(steps, MigrationStep{Kind: AddReverse, Predicate: sp.name})
+	} else if !sp.reverse && sn.Reverse {
+		steps = append(steps, MigrationStep{Kind: RemoveReverse, Predicate: sp.name, Destructive: true})
+	}
+	if sp.count && !sn.Count {
+		steps = append(steps, MigrationStep{Kind: AddCount, Predicate: sp.name})
+	} else if !sp.count && sn.Count {
+		steps = append(steps, MigrationStep{Kind: RemoveCount, Predicate: sp.name, Destructive: true})
+	}
+	return steps
+}
@@ -0,0 +1,361 @@
+package dgogm_test
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+	"testing"
+
+	"github.com/akshaydeo/dgogm"
+	"github.com/dgraph-io/dgraph/protos"
+)
+
+func TestAddMutation(t *testing.T) {
+	d := &Dog{Id: 1, Name: "jarvis", Likes: []Place{{Id: 2, Name: "park"}}, BornAt: &Place{Id: 3, Name: "home"}}
+	m, err := dgogm.AddMutation(d)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Whole object graph is built into a single request, LivesAt is not given so it is skipped
+	if m != `set <0x51a7841f167dabad> <_xid_> "1_dog" .
+set <0x51a7841f167dabad> <uid> "1" .
+set <0x51a7841f167dabad> <name> "jarvis" .
+set <0x95197ab5b88df9a1> <_xid_> "2_place" .
+set <0x95197ab5b88df9a1> <uid> "2" .
+set <0x95197ab5b88df9a1> <name> "park" .
+set <0x51a7841f167dabad> <likes_places> <0x95197ab5b88df9a1> .
+set <0xcac342aaf75a6256> <_xid_> "3_place" .
+set <0xcac342aaf75a6256> <uid> "3" .
+set <0xcac342aaf75a6256> <name> "home" .
+set <0x51a7841f167dabad> <born_at> <0xcac342aaf75a6256> .` {
+		t.Fatal(m)
+	}
+}
+
+type Counter struct {
+	Id   int     `dgraph:"uid"`
+	Hits uint64  `dgraph:"hits"`
+	Max  *uint64 `dgraph:"max"`
+}
+
+func TestAddMutationWithUintOverflow(t *testing.T) {
+	max := uint64(math.MaxInt64)
+	m, err := dgogm.AddMutation(&Counter{Id: 1, Hits: math.MaxInt64, Max: &max})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(m, "<hits> \"9223372036854775807\"") || !strings.Contains(m, "<max> \"9223372036854775807\"") {
+		t.Fatal(m)
+	}
+	// Values above math.MaxInt64 are rejected instead of being wrapped into negative ints
+	_, err = dgogm.AddMutation(&Counter{Id: 1, Hits: math.MaxUint64})
+	if err != (dgogm.ErrOverflow{Field: "Hits", Value: math.MaxUint64}) {
+		t.Fatal(err)
+	}
+	max++
+	_, err = dgogm.AddMutation(&Counter{Id: 1, Max: &max})
+	if err != (dgogm.ErrOverflow{Field: "Max", Value: max}) {
+		t.Fatal(err)
+	}
+}
+
+func TestAddMutationWithMaps(t *testing.T) {
+	p := &Product{Id: "collar", Attrs: map[string]string{"size": "m", "color": "red"}}
+	m, err := dgogm.AddMutation(p)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Keys removed from the map are deleted by clearing the map node first
+	if m != `clear <0x9058d472eeace94f> <*> * .
+set <0xb90a23ccc9be6754> <_xid_> "collar_product" .
+set <0xb90a23ccc9be6754> <uid> "collar" .
+set <0x9058d472eeace94f> <_xid_> "13333509009248773972_attrs" .
+set <0x9058d472eeace94f> <color> "red" .
+set <0x9058d472eeace94f> <size> "m" .
+set <0xb90a23ccc9be6754> <attrs> <0x9058d472eeace94f> .` {
+		t.Fatal(m)
+	}
+	p.Attrs["bad key"] = "x"
+	_, err = dgogm.AddMutation(p)
+	if err == nil {
+		t.Fatal("Keys which are not predicate names should fail")
+	}
+}
+
+func TestUpdateMutation(t *testing.T) {
+	// Nested place loaded without its name keeps the name, place which is not given is cleared
+	d := &Dog{Id: 1, Name: "jarvis", BornAt: &Place{Id: 3}}
+	m, err := dgogm.UpdateMutation(d)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if m != `delete <0x51a7841f167dabad> <color> * .
+delete <0x51a7841f167dabad> <likes_places> * .
+delete <0x51a7841f167dabad> <nicknames> * .
+delete <0x51a7841f167dabad> <lives_at> * .
+set <0x51a7841f167dabad> <_xid_> "1_dog" .
+set <0x51a7841f167dabad> <uid> "1" .
+set <0x51a7841f167dabad> <name> "jarvis" .
+set <0xcac342aaf75a6256> <_xid_> "3_place" .
+set <0xcac342aaf75a6256> <uid> "3" .
+set <0x51a7841f167dabad> <born_at> <0xcac342aaf75a6256> .` {
+		t.Fatal(m)
+	}
+}
+
+func TestUpdateMutationWithFields(t *testing.T) {
+	d := &Dog{Id: 1, Name: "jarvis", Nicknames: []string{"jj"}, Likes: []Place{{Id: 2, Name: "park"}}}
+	// Lists and edges are replaced, nested structs are only connected
+	m, err := dgogm.UpdateMutation(d, "Nicknames", "likes_places")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if m != `clear <0x51a7841f167dabad> <nicknames> * .
+clear <0x51a7841f167dabad> <likes_places> * .
+set <0x51a7841f167dabad> <_xid_> "1_dog" .
+set <0x51a7841f167dabad> <nicknames> "jj" .
+set <0x51a7841f167dabad> <likes_places> <0x95197ab5b88df9a1> .` {
+		t.Fatal(m)
+	}
+	_, err = dgogm.UpdateMutation(d, "age")
+	if err == nil {
+		t.Fatal("Unknown fields should fail")
+	}
+}
+
+func TestPatchMutation(t *testing.T) {
+	color := "white"
+	d := &Dog{Id: 1, Color: &color, Nicknames: []string{"jj"}}
+	m, err := dgogm.PatchMutation(d, map[string]interface{}{"name": "friday", "color": nil, "nicknames": []string{"ff"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if m != `clear <0x51a7841f167dabad> <nicknames> * .
+delete <0x51a7841f167dabad> <color> * .
+set <0x51a7841f167dabad> <_xid_> "1_dog" .
+set <0x51a7841f167dabad> <name> "friday" .
+set <0x51a7841f167dabad> <nicknames> "ff" .` {
+		t.Fatal(m)
+	}
+	if d.Name != "friday" || d.Color != nil || len(d.Nicknames) != 1 || d.Nicknames[0] != "ff" {
+		t.Fatal(d)
+	}
+	// Struct is left untouched when any of the values is invalid
+	_, err = dgogm.PatchMutation(d, map[string]interface{}{"color": "black", "unknown": 1})
+	if err == nil {
+		t.Fatal("Unknown fields should fail")
+	}
+	if d.Color != nil {
+		t.Fatal(d)
+	}
+}
+
+func TestUpsertQuery(t *testing.T) {
+	q, err := dgogm.UpsertQuery(&Owner1{Id: "akshay", Email: "akshay@example.com"}, "Email")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if q != `{Owner1(func: eq(email, "akshay@example.com"), first: 2){_xid_ _uid_ email}}` {
+		t.Fatal(q)
+	}
+	_, err = dgogm.UpsertQuery(&Owner1{Id: "akshay"}, "Email")
+	if !errors.As(err, &dgogm.ErrRequired{}) {
+		t.Fatal(err)
+	}
+}
+
+type Owner2 struct {
+	Email Email              `dgraph:"email"`
+	Phone dgogm.Null[string] `dgraph:"phone"`
+}
+
+func TestUpsertQueryWithNamedTypes(t *testing.T) {
+	q, err := dgogm.UpsertQuery(&Owner2{Email: "akshay@example.com", Phone: dgogm.NullOf("123")}, "Email", "Phone")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if q != `{Owner2(func: eq(email, "akshay@example.com"), first: 2) @filter((eq(email, "akshay@example.com") AND eq(phone, "123"))){_xid_ _uid_ email phone}}` {
+		t.Fatal(q)
+	}
+}
+
+func TestUpsertMutation(t *testing.T) {
+	o := &Owner1{Id: "someone_else", Email: "akshay@example.com", Name: "Akshay Deo"}
+	existing := &protos.Node{Properties: []*protos.Property{
+		{Prop: "_uid_", Value: &protos.Value{Val: &protos.Value_UidVal{UidVal: 0x1a}}},
+		{Prop: "_xid_", Value: &protos.Value{Val: &protos.Value_StrVal{StrVal: "akshay_owner1"}}},
+	}}
+	// Existing node is written keeping its _xid_
+	m, err := dgogm.UpsertMutation(o, []*protos.Node{{Children: []*protos.Node{existing}}}, "email")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if m != `set <0x1a> <_xid_> "akshay_owner1" .
+set <0x1a> <uid> "someone_else" .
+set <0x1a> <email> "akshay@example.com" .
+set <0x1a> <name> "Akshay Deo" .` {
+		t.Fatal(m)
+	}
+	_, err = dgogm.UpsertMutation(o, []*protos.Node{{Children: []*protos.Node{existing, existing}}}, "email")
+	if err != dgogm.ErrMultipleNodes {
+		t.Fatal(err)
+	}
+}
+
+func TestWriteBack(t *testing.T) {
+	m := &Member{Xid: "tony"}
+	toy := &Toy{Xid: "ball_toy"}
+	resp := &protos.Response{AssignedUids: map[string]uint64{"n2fe0e7ef38a00da5": 0x2a, "n84d77b00a65e3bfb": 0x2b}}
+	names, err := dgogm.WriteBack(dgogm.ServerAssigned, resp, m, toy)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Blank nodes are named after the hash of their identity
+	if len(names) != 2 || names[0] != "n2fe0e7ef38a00da5" || names[1] != "n84d77b00a65e3bfb" {
+		t.Fatal(names)
+	}
+	if m.Uid != "0x2a" || m.Xid != "tony" {
+		t.Fatal(m)
+	}
+	if toy.Uid != 0x2b || toy.Xid != "ball_toy" {
+		t.Fatal(toy)
+	}
+	// Hashed nodes are written back with their uids
+	toy = &Toy{Xid: "ball_toy"}
+	names, err = dgogm.WriteBack(nil, nil, toy)
+	if err != nil || names[0] != "" || toy.Uid == 0 || toy.Xid != "ball_toy" {
+		t.Fatal(toy)
+	}
+}
+
+func TestAddMutationWithWrittenBackUid(t *testing.T) {
+	w := &Walker{Name: "tony"}
+	_, err := dgogm.WriteBack(nil, nil, w)
+	if err != nil || w.Uid == 0 {
+		t.Fatal(w)
+	}
+	// Adding again writes into the same node
+	m, err := dgogm.AddMutation(w)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := fmt.Sprintf("set <%#x> <_xid_> \"%d_walker\" .\nset <%#x> <name> \"tony\" .", w.Uid, w.Uid, w.Uid)
+	if m != expected {
+		t.Fatal(m)
+	}
+	q, err := dgogm.Find(nil, &Walker{Uid: w.Uid}).Query()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if q != fmt.Sprintf("{Walker(func: uid(%#x)){_xid_ _uid_ name}}", w.Uid) {
+		t.Fatal(q)
+	}
+}
+
+type Badge struct {
+	Uid   uint64            `dgraph:"_uid_"`
+	Name  string            `dgraph:"name"`
+	Attrs map[string]string `dgraph:"attrs"`
+}
+
+func TestAddMutationWithServerAssignedMaps(t *testing.T) {
+	dgogm.RegisterIdentityStrategy(&Badge{}, dgogm.ServerAssigned)
+	defer dgogm.RegisterIdentityStrategy(&Badge{}, nil)
+	b := &Badge{Name: "gold", Attrs: map[string]string{"color": "yellow"}}
+	// Map of the new node is written once its uid is assigned
+	m, maps, err := dgogm.AddMutations(b, 0x2a)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Nothing is cleared for the new nodes
+	if !strings.Contains(m, "<name> \"gold\"") || strings.Contains(m, "attrs") || strings.Contains(m, "clear") {
+		t.Fatal(m)
+	}
+	if maps != `set <0xa043a434aed24c9c> <_xid_> "42_attrs" .
+set <0xa043a434aed24c9c> <color> "yellow" .
+set <0x2a> <attrs> <0xa043a434aed24c9c> .` || b.Uid != 0x2a {
+		t.Fatal(maps)
+	}
+	// Adding again writes into the same map node
+	m, err = dgogm.AddMutation(b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if m != `clear <0xa043a434aed24c9c> <*> * .
+set <0x2a> <_xid_> "42_badge" .
+set <0x2a> <name> "gold" .
+set <0xa043a434aed24c9c> <_xid_> "42_attrs" .
+set <0xa043a434aed24c9c> <color> "yellow" .
+set <0x2a> <attrs> <0xa043a434aed24c9c> .` {
+		t.Fatal(m)
+	}
+	// Single map node is found again
+	f := &Badge{}
+	dgogm.ParseNodeTo(&protos.Node{
+		Attribute:  "Badge",
+		Properties: []*protos.Property{{Prop: "_uid_", Value: &protos.Value{Val: &protos.Value_UidVal{UidVal: 0x2a}}}},
+		Children: []*protos.Node{{
+			Attribute:  "attrs",
+			Properties: []*protos.Property{{Prop: "color", Value: &protos.Value{Val: &protos.Value_StrVal{StrVal: "yellow"}}}},
+		}},
+	}, f)
+	if f.Uid != 0x2a || f.Attrs["color"] != "yellow" {
+		t.Fatal(f)
+	}
+}
+
+func TestDeleteMutation(t *testing.T) {
+	p := &Product{Id: "collar", Attrs: map[string]string{"size": "m"}}
+	// Map node owned by the product is deleted too
+	m, err := dgogm.DeleteMutation(p, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if m != `delete <0xb90a23ccc9be6754> <*> * .
+delete <0x9058d472eeace94f> <*> * .` {
+		t.Fatal(m)
+	}
+	_, err = dgogm.DeleteMutation(Product{}, false)
+	if err != dgogm.ErrInvalidTarget {
+		t.Fatal(err)
+	}
+}
+
+func TestDeleteCascadeMutation(t *testing.T) {
+	d := &Dog{Id: 1, Likes: []Place{{Id: 2}, {Id: 2}}, BornAt: &Place{Id: 3}}
+	// Nodes of the nested structs are deleted once
+	m, err := dgogm.DeleteMutation(d, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if m != `delete <0x51a7841f167dabad> <*> * .
+delete <0x95197ab5b88df9a1> <*> * .
+delete <0xcac342aaf75a6256> <*> * .` {
+		t.Fatal(m)
+	}
+	m, err = dgogm.DeleteMutation(d, false)
+	if err != nil || m != "delete <0x51a7841f167dabad> <*> * ." {
+		t.Fatal(m)
+	}
+}
+
+func TestDeleteEdgeMutation(t *testing.T) {
+	d := &Dog{Id: 1}
+	m, err := dgogm.DeleteEdgeMutation(d, "Likes", &Place{Id: 2})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if m != "delete <0x51a7841f167dabad> <likes_places> <0x95197ab5b88df9a1> ." {
+		t.Fatal(m)
+	}
+	// All the relations are deleted without target
+	m, err = dgogm.DeleteEdgeMutation(d, "likes_places", nil)
+	if err != nil || m != "delete <0x51a7841f167dabad> <likes_places> * ." {
+		t.Fatal(m)
+	}
+	_, err = dgogm.DeleteEdgeMutation(d, "owner", nil)
+	if err == nil {
+		t.Fatal("Unknown edges should fail")
+	}
+}
@@ -0,0 +1,81 @@
+package dgogm
+
+import (
+	"reflect"
+)
+
+// Null is a value which can be explicitly cleared in dgraph
+// Zero value of Null is unset, which is neither written nor cleared by Add and Update
+// NullOf gives a value which is always written even if it's zero, Cleared gives a value which deletes the predicate
+type Null[T any] struct {
+	Value T
+	Valid bool
+	null  bool
+}
+
+// This function returns Null holding the given value
+func NullOf[T any](v T) Null[T] {
+	return Null[T]{Value: v, Valid: true}
+}
+
+// This function returns Null which deletes the predicate when added or updated
+func Cleared[T any]() Null[T] {
+	return Null[T]{null: true}
+}
+
+// This function returns the value and if it is valid
+func (n Null[T]) Get() (T, bool) {
+	return n.Value, n.Valid
+}
+
+// This function returns if the value is explicitly cleared
+func (n Null[T]) IsNull() bool {
+	return n.null
+}
+
+// This function returns if the value is either set or explicitly cleared
+func (n Null[T]) IsSet() bool {
+	return n.Valid || n.null
+}
+
+// This function returns the value stored in dgraph, nil if it's not valid
+func (n Null[T]) MarshalDgraph() (interface{}, error) {
+	if !n.Valid {
+		return nil, nil
+	}
+	return marshalValue(reflect.ValueOf(&n.Value).Elem())
+}
+
+// This function sets the value returned by dgraph
+func (n *Null[T]) UnmarshalDgraph(val interface{}) error {
+	v, ok := convertTo(val, n.valueType())
+	if !ok {
+		return ErrUnsupportedType{Type: reflect.TypeOf(val)}
+	}
+	n.Value = v.Interface().(T)
+	n.Valid = true
+	n.null = false
+	return nil
+}
+
+// This function returns the type of the value held
+func (n Null[T]) valueType() reflect.Type {
+	return reflect.TypeOf((*T)(nil)).Elem()
+}
+
+// nullable is implemented by Null for all the types it can hold
+type nullable interface {
+	IsNull() bool
+	IsSet() bool
+	valueType() reflect.Type
+}
+
+var nullableType = reflect.TypeOf((*nullable)(nil)).Elem()
+
+// This function returns the type held by Null, false is returned if the given type is not Null
+func nullValueType(t reflect.Type) (reflect.Type, bool) {
+	if t.Kind() != reflect.Struct || !t.Implements(nullableType) {
+		return nil, false
+	}
+	return reflect.Zero(t).Interface().(nullable).valueType(), true
+}
@@ -0,0 +1,55 @@
+package dgogm_test
+
+import (
+	"testing"
+
+	"github.com/akshaydeo/dgogm"
+)
+
+func TestNull(t *testing.T) {
+	var unset dgogm.Null[int]
+	if unset.IsSet() || unset.IsNull() {
+		t.Fail()
+	}
+	zero := dgogm.NullOf(0)
+	if v, ok := zero.Get(); !ok || v != 0 || !zero.IsSet() || zero.IsNull() {
+		t.Fail()
+	}
+	cleared := dgogm.Cleared[string]()
+	if _, ok := cleared.Get(); ok || !cleared.IsSet() || !cleared.IsNull() {
+		t.Fail()
+	}
+}
+
+func TestNull_UnmarshalDgraph(t *testing.T) {
+	n := dgogm.Cleared[int]()
+	err := n.UnmarshalDgraph(int64(0))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if v, ok := n.Get(); !ok || v != 0 || n.IsNull() {
+		t.Fail()
+	}
+	if n.UnmarshalDgraph("zero") == nil {
+		t.Fail()
+	}
+}
+
+type Profile struct {
+	Id       string              `dgraph:"uid"`
+	Nickname dgogm.Null[string]  `dgraph:"nickname"`
+	Active   dgogm.Null[bool]    `dgraph:"active"`
+	Score    dgogm.Null[float64] `dgraph:"score"`
+}
+
+func TestSchemaNull(t *testing.T) {
+	schema := dgogm.Schema(&Profile{})
+	expected := `_xid_: string @index(exact) .
+uid: string .
+nickname: string .
+active: bool .
+score: float .`
+	if schema != expected {
+		t.Fatal(schema)
+	}
+}
@@ -0,0 +1,97 @@
+package dgogm
+
+import (
+	"context"
+	"crypto/tls"
+	"time"
+
+	"github.com/dgraph-io/dgraph/client"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+)
+
+// This struct holds the connection options given to Connect
+type options struct {
+	tls           *tls.Config
+	dialTimeout   time.Duration
+	clientDir     string
+	clientOptions client.BatchMutationOptions
+	dialOptions   []grpc.DialOption
+}
+
+// Option configures the connection created by Connect
+type Option func(*options)
+
+// This function returns the default connection options
+// Insecure connections, 30 seconds dial timeout and client.DefaultOptions
+func defaultOptions() *options {
+	return &options{
+		dialTimeout:   time.Second * 30,
+		clientOptions: client.DefaultOptions,
+	}
+}
+
+// This function returns the grpc dial options for these options
+func (o *options) grpcDialOptions() []grpc.DialOption {
+	opts := []grpc.DialOption{grpc.WithBlock()}
+	if o.tls != nil {
+		opts = append(opts, grpc.WithTransportCredentials(credentials.NewTLS(o.tls)))
+	} else {
+		opts = append(opts, grpc.WithInsecure())
+	}
+	return append(opts, o.dialOptions...)
+}
+
+// This option connects to the dgraph servers over TLS using the given config
+func WithTLS(config *tls.Config) Option {
+	return func(o *options) {
+		o.tls = config
+	}
+}
+
+// This option sets the maximum time for dialing each of the addresses, 0 means no timeout
+func WithDialTimeout(timeout time.Duration) Option {
+	return func(o *options) {
+		o.dialTimeout = timeout
+	}
+}
+
+// This option sets the client dir used by dgraph client for its cache
+// Unlike the auto generated dir, this dir is never removed by dgogm
+func WithClientDir(clientDir string) Option {
+	return func(o *options) {
+		o.clientDir = clientDir
+	}
+}
+
+// This option sets the batch mutation options of dgraph client
+func WithClientOptions(clientOptions client.BatchMutationOptions) Option {
+	return func(o *options) {
+		o.clientOptions = clientOptions
+	}
+}
+
+// This option appends the given grpc dial options to the ones created by dgogm
+func WithGRPCDialOptions(dialOptions ...grpc.DialOption) Option {
+	return func(o *options) {
+		o.dialOptions = append(o.dialOptions, dialOptions...)
+	}
+}
+
+// This option sends the given metadata i.e. authorization tokens with every call
+func WithAuthMetadata(metadata map[string]string) Option {
+	return func(o *options) {
+		o.dialOptions = append(o.dialOptions, grpc.WithPerRPCCredentials(authMetadata(metadata)))
+	}
+}
+
+// authMetadata implements credentials.PerRPCCredentials for sending static metadata with every call
+type authMetadata map[string]string
+
+func (am authMetadata) GetRequestMetadata(ctx context.Context, uri ...string) (map[string]string, error) {
+	return am, nil
+}
+
+func (am authMetadata) RequireTransportSecurity() bool {
+	return false
+}
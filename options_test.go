@@ -0,0 +1,66 @@
+package dgogm_test
+
+import (
+	"context"
+	"crypto/tls"
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/akshaydeo/dgogm"
+	"github.com/dgraph-io/dgraph/client"
+	"google.golang.org/grpc"
+)
+
+func TestConnectDefaultOptions(t *testing.T) {
+	o := dgogm.ApplyOptions()
+	if o.TLS || o.DialTimeout != 30*time.Second || o.ClientOptions != client.DefaultOptions {
+		t.Fatal(o)
+	}
+	// Client dir is generated and removed on Close
+	if !o.TempDir || !strings.HasPrefix(o.ClientDir, os.TempDir()+"/dgraph/") {
+		t.Fatal(o)
+	}
+	// Blocking and insecure
+	if o.DialOptions != 2 {
+		t.Fatal(o)
+	}
+}
+
+func TestConnectOptions(t *testing.T) {
+	clientOptions := client.BatchMutationOptions{Size: 10, Pending: 2}
+	o := dgogm.ApplyOptions(
+		dgogm.WithTLS(&tls.Config{}),
+		dgogm.WithDialTimeout(time.Second),
+		dgogm.WithClientDir("/tmp/dgogm"),
+		dgogm.WithClientOptions(clientOptions),
+		dgogm.WithGRPCDialOptions(grpc.WithBlock()),
+		dgogm.WithAuthMetadata(map[string]string{"authorization": "Bearer token"}),
+	)
+	if !o.TLS || o.DialTimeout != time.Second || o.ClientOptions != clientOptions {
+		t.Fatal(o)
+	}
+	// Client dir given by the caller is never removed
+	if o.TempDir || o.ClientDir != "/tmp/dgogm" {
+		t.Fatal(o)
+	}
+	// Given dial options are appended to the ones created by dgogm
+	if o.DialOptions != 4 {
+		t.Fatal(o)
+	}
+}
+
+func TestAuthMetadata(t *testing.T) {
+	metadata := map[string]string{"authorization": "Bearer token"}
+	creds := dgogm.AuthMetadata(metadata)
+	m, err := creds.GetRequestMetadata(context.Background())
+	if err != nil || !reflect.DeepEqual(m, metadata) {
+		t.Fatal(m, err)
+	}
+	// Metadata is sent over insecure connections too
+	if creds.RequireTransportSecurity() {
+		t.Fatal("requires transport security")
+	}
+}
@@ -0,0 +1,172 @@
+package dgogm
+
+import (
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/dgraph-io/dgraph/protos"
+)
+
+// This struct holds pagination and ordering arguments of the root or of a nested edge
+type page struct {
+	first  int
+	offset int
+	after  uint64
+	orders []string
+}
+
+// This function converts the page to dgraph query arguments
+func (p *page) String() string {
+	args := append([]string{}, p.orders...)
+	if p.first != 0 {
+		args = append(args, fmt.Sprintf("first: %d", p.first))
+	}
+	if p.offset != 0 {
+		args = append(args, fmt.Sprintf("offset: %d", p.offset))
+	}
+	if p.after != 0 {
+		args = append(args, fmt.Sprintf("after: 0x%x", p.after))
+	}
+	return strings.Join(args, ", ")
+}
+
+// This function returns the page for the given edge, creating it if needed
+// Edge is resolved through the struct tags, so that Likes and likes_places share the same page
+func (dq *DgQuery) page(edge string) *page {
+	path, _, err := resolveEdgePath(dq.structType(), edge)
+	if err != nil {
+		dq.err = err
+		return &page{}
+	}
+	if dq.pages == nil {
+		dq.pages = map[string]*page{}
+	}
+	if dq.pages[path] == nil {
+		dq.pages[path] = &page{}
+	}
+	return dq.pages[path]
+}
+
+// This function limits the number of results to n
+// If edge is given, the limit is applied on that nested edge i.e. First(2, "likes_places")
+func (dq *DgQuery) First(n int, edge ...string) *DgQuery {
+	dq.page(strings.Join(edge, ".")).first = n
+	return dq
+}
+
+// This function skips first n results
+// If edge is given, the offset is applied on that nested edge i.e. Offset(2, "likes_places")
+func (dq *DgQuery) Offset(n int, edge ...string) *DgQuery {
+	dq.page(strings.Join(edge, ".")).offset = n
+	return dq
+}
+
+// This function returns the results after the node with given uid
+// Results are paged in the order of uids, so it can not be combined with OrderAsc or OrderDesc
+func (dq *DgQuery) After(uid uint64) *DgQuery {
+	dq.page("").after = uid
+	return dq
+}
+
+// This function returns the results after the given cursor returned by Cursor or ExecuteWithCursor
+// Results are paged in the order of uids, so it can not be combined with OrderAsc or OrderDesc
+func (dq *DgQuery) AfterCursor(cursor string) *DgQuery {
+	uid, err := decodeCursor(cursor)
+	if err != nil {
+		dq.err = err
+		return dq
+	}
+	return dq.After(uid)
+}
+
+// This function orders the results in ascending order of the given field
+// Nested edges are ordered by using dotted path i.e. OrderAsc("likes_places.name")
+func (dq *DgQuery) OrderAsc(field string) *DgQuery {
+	return dq.order("orderasc", field)
+}
+
+// This function orders the results in descending order of the given field
+// Nested edges are ordered by using dotted path i.e. OrderDesc("likes_places.name")
+func (dq *DgQuery) OrderDesc(field string) *DgQuery {
+	return dq.order("orderdesc", field)
+}
+
+func (dq *DgQuery) order(direction, field string) *DgQuery {
+	edge := ""
+	if i := strings.LastIndex(field, "."); i != -1 {
+		edge, field = field[:i], field[i+1:]
+	}
+	_, et, err := resolveEdgePath(dq.structType(), edge)
+	if err != nil {
+		dq.err = err
+		return dq
+	}
+	f, ok := lookupField(et, field)
+	if !ok {
+		dq.err = fmt.Errorf("%s does not have field %s", et.Name(), field)
+		return dq
+	}
+	p := dq.page(edge)
+	p.orders = append(p.orders, fmt.Sprintf("%s: %s", direction, getFieldName(f)))
+	return dq
+}
+
+// This function converts pages of this query to the dgraph arguments keyed by edge path
+func (dq *DgQuery) pageArgs() map[string]string {
+	args := map[string]string{}
+	for path, p := range dq.pages {
+		args[path] = p.String()
+	}
+	return args
+}
+
+// This function resolves the given dotted edge path into dgraph predicates
+// and returns the struct type at the end of the path
+func resolveEdgePath(t reflect.Type, edge string) (string, reflect.Type, error) {
+	if edge == "" {
+		return "", t, nil
+	}
+	path := []string{}
+	for _, segment := range strings.Split(edge, ".") {
+		f, ok := lookupField(t, segment)
+		if !ok {
+			return "", nil, fmt.Errorf("%s does not have field %s", t.Name(), edge)
+		}
+		nt, ok := nodeType(f.Type)
+		if !ok || isPrimitiveType(f.Type) {
+			return "", nil, fmt.Errorf("%s is not a relation in %s", getFieldName(f), edge)
+		}
+		path = append(path, getFieldName(f))
+		t = nt
+	}
+	return strings.Join(path, "."), t, nil
+}
+
+// This function returns an opaque cursor pointing to the last of the given nodes
+func cursorFor(nodes []*protos.Node) string {
+	if len(nodes) == 0 {
+		return ""
+	}
+	uid, ok := nodeUid(nodes[len(nodes)-1])
+	if !ok {
+		return ""
+	}
+	return base64.RawURLEncoding.EncodeToString([]byte(strconv.FormatUint(uid, 36)))
+}
+
+// This function converts the opaque cursor back to the uid
+func decodeCursor(cursor string) (uint64, error) {
+	data, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return 0, errors.New("Invalid cursor")
+	}
+	uid, err := strconv.ParseUint(string(data), 36, 64)
+	if err != nil {
+		return 0, errors.New("Invalid cursor")
+	}
+	return uid, nil
+}
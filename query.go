@@ -1,13 +1,14 @@
 package dgogm
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"reflect"
+	"strings"
 
 	"github.com/dgraph-io/dgraph/client"
 	"github.com/dgraph-io/dgraph/protos"
-	"github.com/media-net/cargo/logger"
 )
 
 // This struct defines a query to the dgraph
@@ -15,7 +16,15 @@ type DgQuery struct {
 	s      interface{}
 	id     interface{}
 	fields []string
+	filter Filter
+	all    bool
+	pages  map[string]*page
+	counts []string
+	cursor string
+	err    error
 	client *client.Dgraph
+	logger Logger
+	ids    IdentityStrategy
 }
 
 func (dq *DgQuery) Id(id interface{}) *DgQuery {
@@ -23,43 +32,209 @@ func (dq *DgQuery) Id(id interface{}) *DgQuery {
 	return dq
 }
 
+// This function limits the query to the given fields
+// Fields can be go field names or dgraph names, nested fields are separated by dots
+// i.e. Fields("name", "likes_places.name")
+// Fields which are not selected are left untouched in the struct
 func (dq *DgQuery) Fields(fields ...string) *DgQuery {
 	dq.fields = fields
 	return dq
 }
 
-func (dq *DgQuery) Execute() error {
-	t := reflect.TypeOf(dq.s).Elem()
-	var err error
-	var nodes []*protos.Node
-	var qfields string
+// This function filters the nodes using the given filters instead of looking up the node by its id
+// Multiple filters are combined with AND
+// i.e. Where(Eq("name", "jarvis"), Or(Has("color"), Not(Gt("Height", 100))))
+func (dq *DgQuery) Where(filters ...Filter) *DgQuery {
+	if len(filters) == 1 {
+		dq.filter = filters[0]
+		return dq
+	}
+	dq.filter = And(filters...)
+	return dq
+}
+
+// This function returns the dgraph query which will be fired by Execute
+func (dq *DgQuery) Query() (string, error) {
+	if dq.err != nil {
+		return "", dq.err
+	}
+	t := dq.structType()
+	if dq.err != nil {
+		return "", dq.err
+	}
+	fields := FieldMap{}
 	if dq.fields == nil || len(dq.fields) == 0 {
-		fields := FieldMap{}
-		Debug("%s", fields.String())
 		getFieldMap(t, "", fields)
-		qfields = fields.String()
-		goto execute
+	} else {
+		// prepare fields from the given array
+		err := getProjectedFieldMap(t, dq.fields, fields)
+		if err != nil {
+			return "", err
+		}
+	}
+	for _, c := range dq.counts {
+		if !fields.has("", c) {
+			fields.Add("", c)
+		}
+	}
+	if p := dq.pages[""]; p != nil && p.after != 0 && len(p.orders) != 0 {
+		// Dgraph pages by uid after the given node, which skips or repeats the results when ordered
+		return "", errors.New("After can not be combined with OrderAsc or OrderDesc")
+	}
+	args := dq.pageArgs()
+	root, filter, err := dq.root(t)
+	if err != nil {
+		return "", err
+	}
+	if args[""] != "" {
+		root = fmt.Sprintf("%s, %s", root, args[""])
+	}
+	return fmt.Sprintf(GET_NODES_FOR_FILTER, t.Name(), root, filter, fields.withArgs(args)), nil
+}
+
+// This function returns the function used at the root of the query along with the @filter directive
+func (dq *DgQuery) root(t reflect.Type) (string, string, error) {
+	if dq.filter == nil {
+		if dq.all {
+			return "", "", errors.New("FindAll requires filters, use Where")
+		}
+		xid, uid, err := identity(dq.ids, dq.s)
+		if err != nil {
+			return "", "", err
+		}
+		if uid == 0 {
+			// Node is not known by its uid, so it's looked up by its _xid_
+			return fmt.Sprintf("eq(_xid_, %s)", literal(xid)), "", nil
+		}
+		return fmt.Sprintf("uid(0x%x)", uid), "", nil
+	}
+	return rootAndFilter(dq.filter, t)
+}
+
+// This function executes the query, Cursor returns the cursor pointing to the last result afterwards
+func (dq *DgQuery) Execute() error {
+	return dq.ExecuteContext(context.Background())
+}
+
+// This function executes the query, cancelling it when the given context is done
+func (dq *DgQuery) ExecuteContext(ctx context.Context) error {
+	_, err := dq.execute(ctx)
+	return err
+}
+
+// This function executes the query and returns an opaque cursor pointing to the last result
+// The cursor can be passed to AfterCursor for fetching the next page
+func (dq *DgQuery) ExecuteWithCursor() (string, error) {
+	return dq.ExecuteWithCursorContext(context.Background())
+}
+
+// This function executes the query and returns an opaque cursor pointing to the last result
+// The query is cancelled when the given context is done
+func (dq *DgQuery) ExecuteWithCursorContext(ctx context.Context) (string, error) {
+	_, err := dq.execute(ctx)
+	if err != nil {
+		return "", err
+	}
+	return dq.cursor, nil
+}
+
+// This function returns an opaque cursor pointing to the last result of the executed query
+// The cursor can be passed to AfterCursor for fetching the next page, it's empty if there are no results
+func (dq *DgQuery) Cursor() string {
+	return dq.cursor
+}
+
+// This function fires the query and parses the results into the given struct
+// It returns the nodes which were parsed
+func (dq *DgQuery) execute(ctx context.Context) ([]*protos.Node, error) {
+	q, err := dq.Query()
+	if err != nil {
+		return nil, err
 	}
-	// prepare qfields from the given array
-execute:
-	nodes, err = query(dq.client, fmt.Sprintf(GET_NODE_FOR_ID, t.Name(), hash(GetUId(dq.s)), qfields))
+	l := loggerOrDefault(dq.logger)
+	nodes, err := query(ctx, l, dq.client, q)
 	if err != nil {
-		return err
+		return nil, err
+	}
+	var children []*protos.Node
+	if len(nodes) != 0 {
+		children = nodes[0].Children
+	}
+	if dq.all {
+		parseNodesTo(l, children, dq.s)
+		dq.cursor = cursorFor(children)
+		return children, nil
 	}
-	if len(nodes) == 0 {
-		errors.New("No results found")
+	if len(children) == 0 {
+		return nil, ErrNotFound
 	}
+	parseNodeTo(l, children[0], dq.s)
+	dq.cursor = cursorFor(children[:1])
+	return children[:1], nil
+}
 
-	parseNodeTo(nodes[0].Children[0], dq.s)
-	return nil
+// This function returns the struct type this query is fetching
+// For FindAll it's the element type of the slice
+// If the target is not valid, ErrInvalidTarget is recorded and an empty struct type is returned
+func (dq *DgQuery) structType() reflect.Type {
+	t := reflect.TypeOf(dq.s)
+	if t != nil && t.Kind() == reflect.Ptr && !reflect.ValueOf(dq.s).IsNil() {
+		t = t.Elem()
+		if dq.all && t.Kind() == reflect.Slice {
+			t = t.Elem()
+			if t.Kind() == reflect.Ptr {
+				t = t.Elem()
+			}
+			if t.Kind() == reflect.Struct {
+				return t
+			}
+		} else if !dq.all && t.Kind() == reflect.Struct {
+			return t
+		}
+	}
+	if dq.err == nil {
+		dq.err = ErrInvalidTarget
+	}
+	return reflect.TypeOf(struct{}{})
+}
+
+// This function parses each of the protos.Node into a new element of the given pointer to slice
+func parseNodesTo(l Logger, nodes []*protos.Node, p interface{}) {
+	v := reflect.ValueOf(p).Elem()
+	et := v.Type().Elem()
+	slice := reflect.MakeSlice(v.Type(), 0, len(nodes))
+	for _, n := range nodes {
+		switch et.Kind() {
+		case reflect.Ptr:
+			nf := reflect.New(et.Elem())
+			parseNodeTo(l, n, nf.Interface())
+			slice = reflect.Append(slice, nf)
+		case reflect.Struct:
+			nf := reflect.New(et)
+			parseNodeTo(l, n, nf.Interface())
+			slice = reflect.Append(slice, nf.Elem())
+		}
+	}
+	v.Set(slice)
 }
 
 // This function converts proto.Node to a map
-func nodeMap(n *protos.Node) map[string]interface{} {
+func nodeMap(l Logger, n *protos.Node) map[string]interface{} {
 	m := map[string]interface{}{}
 	for _, p := range n.Properties {
 		v, err := convert(p.Value)
 		if err != nil {
+			l.Warn("Invalid value", "property", p.Prop, "error", err)
+			continue
+		}
+		// List predicates are returned as multiple properties with the same name
+		if prev, ok := m[p.Prop]; ok {
+			switch prev.(type) {
+			case []interface{}:
+				m[p.Prop] = append(prev.([]interface{}), v)
+			default:
+				m[p.Prop] = []interface{}{prev, v}
+			}
 			continue
 		}
 		m[p.Prop] = v
@@ -81,59 +256,105 @@ func nodeMap(n *protos.Node) map[string]interface{} {
 	return m
 }
 
+// This function returns the _uid_ of the given node, false is returned if it was not queried
+func nodeUid(n *protos.Node) (uint64, bool) {
+	for _, p := range n.Properties {
+		if p.Prop == "_uid_" {
+			return p.Value.GetUidVal(), true
+		}
+	}
+	return 0, false
+}
+
 // This function parses protos.Node to fill data into given interface
-func parseNodeTo(n *protos.Node, p interface{}) {
+func parseNodeTo(l Logger, n *protos.Node, p interface{}) {
 	v := reflect.ValueOf(p)
 	t := reflect.TypeOf(p)
 	// Fetching properties from the node
-	props := nodeMap(n)
-	for i := 0; i < v.Elem().NumField(); i++ {
-		fname := getFieldName(t.Elem().Field(i))
+	props := nodeMap(l, n)
+	for _, f := range nodeFields(t.Elem()) {
+		fname := getFieldName(f)
 		if fname == "-" {
 			continue
 		}
+		if fname == "_uid_" {
+			if uid, ok := nodeUid(n); ok {
+				setUid(p, uid)
+			}
+			continue
+		}
 		// Search that property and assign the values
 		val, ok := props[fname]
 		if !ok {
-			Debug("Property %s is not present in the results", fname)
+			l.Debug("Property is not present in the results", "property", fname)
 			continue
 		}
-		Debug("%v", val)
-		if !v.Elem().Field(i).IsValid() {
-			logger.W("Invalid field", v.Elem().Field(i).String(), t.Elem().Field(i).Name)
+		// Embedded pointers are initialized only when one of their fields is present
+		fv, ok := fieldValue(v.Elem(), f.Index, true)
+		if !ok || !fv.IsValid() {
+			l.Warn("Invalid field", "field", f.Name)
+			continue
+		}
+		// Numbers and bools tagged with string option are stored as string
+		if _, ok := getFieldOptions(f)["string"]; ok && isPrimitiveType(f.Type) {
+			sv, ok := parseString(val, f.Type)
+			if !ok {
+				l.Warn("Invalid value", "property", fname, "value", val)
+				continue
+			}
+			fv.Set(sv)
 			continue
 		}
-		Debug("%s %s", reflect.ValueOf(val).Type().String(), t.Elem().Field(i).Type)
-		switch v.Elem().Field(i).Kind() {
+		// Scalars like []byte and time.Time are stored as a single predicate
+		if isScalarType(f.Type) {
+			cv, ok := convertTo(val, f.Type)
+			if !ok {
+				l.Warn("Invalid value", "property", fname, "value", val)
+				continue
+			}
+			fv.Set(cv)
+			continue
+		}
+		switch fv.Kind() {
 		case reflect.Slice:
 			// Check if it's slice for primitive type
-			if isPrimitiveType(t.Elem().Field(i).Type.Elem()) {
-				temp := []interface{}{}
-				err := FromJson(val.(string), &temp)
-				if err != nil {
-					continue
-				}
-				Debug("%v %d", temp, len(temp))
-				// Processing slice of primitive datatype
-				// This is technically gonna be json array
-				slice := reflect.MakeSlice(reflect.SliceOf(t.Elem().Field(i).Type.Elem()), 0, len(temp))
-				switch t.Elem().Field(i).Type.Elem().Kind() {
-				case reflect.Struct:
-					Debug("Its a struct")
-					for j := 0; j < len(temp); j++ {
-						slice = reflect.Append(slice, reflect.ValueOf(temp[j]))
+			if isPrimitiveType(f.Type.Elem()) {
+				var values []interface{}
+				if _, ok := getFieldOptions(f)["json"]; ok {
+					// Legacy encoding, this is gonna be json array
+					str, ok := val.(string)
+					if !ok {
+						l.Warn("Invalid json array", "property", fname)
+						continue
 					}
-				case reflect.Ptr:
-					for j := 0; j < len(temp); j++ {
-						ptr := reflect.New(t.Elem().Field(i).Type.Elem().Elem())
-						Debug("%v %v", t.Elem().Field(i).Type.Elem().Elem().Name(), ptr)
-						ptr.Elem().Set(reflect.ValueOf(temp[j]))
-						Debug("Its a ptr %s")
-						slice = reflect.Append(slice, ptr)
+					err := FromJson(str, &values)
+					if err != nil {
+						l.Warn("Invalid json array", "property", fname, "error", err)
+						continue
+					}
+				} else {
+					// List predicate with a single value is returned as the value itself
+					switch val.(type) {
+					case []interface{}:
+						values = val.([]interface{})
+					default:
+						values = []interface{}{val}
+						// Slices were stored as json arrays before the list predicates, those are decoded too
+						if str, ok := val.(string); ok && strings.HasPrefix(str, "[") && FromJson(str, &values) == nil {
+							l.Warn("Legacy json array, update the node for storing it as a list", "property", fname)
+						}
 					}
 				}
-				Debug("%v", slice)
-				v.Elem().Field(i).Set(slice)
+				slice := reflect.MakeSlice(f.Type, 0, len(values))
+				for _, value := range values {
+					ev, ok := convertTo(value, f.Type.Elem())
+					if !ok {
+						l.Warn("Invalid list value", "property", fname, "value", value)
+						continue
+					}
+					slice = reflect.Append(slice, ev)
+				}
+				fv.Set(slice)
 				continue
 			}
 			var nodes []*protos.Node
@@ -143,54 +364,102 @@ func parseNodeTo(n *protos.Node, p interface{}) {
 			case []*protos.Node:
 				nodes = val.([]*protos.Node)
 			}
-			// Checking if the given field is already initialized
-			if v.Elem().Field(i).IsNil() {
-				// Initializing slice
-				v.Elem().Field(i).Set(reflect.MakeSlice(reflect.SliceOf(t.Elem().Field(i).Type.Elem()), 0, len(nodes)))
-			}
-			Debug("Processing slices %d", len(nodes))
+			// Initializing slice, so that reloading a node does not duplicate the elements
+			fv.Set(reflect.MakeSlice(reflect.SliceOf(f.Type.Elem()), 0, len(nodes)))
+			l.Debug("Processing slice", "property", fname, "count", len(nodes))
 			// Iterating and initializing
 			for j := 0; j < len(nodes); j++ {
 				// Check if the elements are of type ptr, things have to be handled a bit differently
-				switch t.Elem().Field(i).Type.Elem().Kind() {
+				switch f.Type.Elem().Kind() {
 				case reflect.Ptr:
-					Debug("its ptr****** %d", j)
-					nf := reflect.New(t.Elem().Field(i).Type.Elem().Elem())
-					parseNodeTo(nodes[j], nf.Interface())
-					v.Elem().Field(i).Set(reflect.Append(v.Elem().Field(i), nf))
+					nf := reflect.New(f.Type.Elem().Elem())
+					parseNodeTo(l, nodes[j], nf.Interface())
+					fv.Set(reflect.Append(fv, nf))
 				case reflect.Struct:
-					Debug("Struct")
-					Debug("%d Its a struct**** %s", j, t.Elem().Field(i).Type.Elem().String())
-					nf := reflect.New(t.Elem().Field(i).Type.Elem())
-					parseNodeTo(nodes[j], nf.Interface())
-					v.Elem().Field(i).Set(reflect.Append(v.Elem().Field(i), nf.Elem()))
-				default:
-					Debug("None")
+					nf := reflect.New(f.Type.Elem())
+					parseNodeTo(l, nodes[j], nf.Interface())
+					fv.Set(reflect.Append(fv, nf.Elem()))
 				}
 			}
 		case reflect.Ptr:
-			if isPrimitiveType(t.Elem().Field(i).Type) {
+			if isPrimitiveType(f.Type) {
 				// This case is pointer to primitive type
-				ptr := reflect.New(t.Elem().Field(i).Type.Elem())
-				Debug("%v %v", t.Elem().Field(i).Type.Elem().Name(), ptr)
-				ptr.Elem().Set(reflect.ValueOf(val))
-				v.Elem().Field(i).Set(ptr)
+				ptr, ok := convertTo(val, f.Type)
+				if !ok {
+					l.Warn("Invalid value", "property", fname, "value", val)
+					continue
+				}
+				fv.Set(ptr)
+				continue
+			}
+			node, ok := val.(*protos.Node)
+			if !ok {
+				l.Warn("Property is not a node", "property", fname)
 				continue
 			}
-			nf := reflect.New(t.Elem().Field(i).Type.Elem())
-			parseNodeTo(val.(*protos.Node), nf.Interface())
-			v.Elem().Field(i).Set(nf)
+			// Reusing the existing struct so that fields which were not queried are left untouched
+			if !fv.IsNil() {
+				parseNodeTo(l, node, fv.Interface())
+				continue
+			}
+			nf := reflect.New(f.Type.Elem())
+			parseNodeTo(l, node, nf.Interface())
+			fv.Set(nf)
 		case reflect.Struct:
-			nf := reflect.New(t.Elem().Field(i).Type)
-			parseNodeTo(val.(*protos.Node), nf.Interface())
-			v.Elem().Field(i).Set(nf.Elem())
+			node, ok := val.(*protos.Node)
+			if !ok {
+				l.Warn("Property is not a node", "property", fname)
+				continue
+			}
+			parseNodeTo(l, node, fv.Addr().Interface())
+		case reflect.Map:
+			if _, ok := getFieldOptions(f)["json"]; ok {
+				str, ok := val.(string)
+				if !ok {
+					l.Warn("Invalid json map", "property", fname)
+					continue
+				}
+				mv := reflect.New(f.Type)
+				err := FromJson(str, mv.Interface())
+				if err != nil {
+					l.Warn("Invalid json map", "property", fname, "error", err)
+					continue
+				}
+				fv.Set(mv.Elem())
+				continue
+			}
+			node, ok := val.(*protos.Node)
+			if !ok || !isMapType(f.Type) {
+				l.Warn("Property is not a node", "property", fname)
+				continue
+			}
+			fv.Set(parseMap(l, node, f.Type))
 		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64, reflect.Float64, reflect.Float32, reflect.String, reflect.Bool, reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
 			// This includes all primitive types
-			Debug("Setting %s with %v", fname, reflect.ValueOf(val))
-			if v.Elem().Field(i).Type() != reflect.ValueOf(val).Type() {
+			l.Debug("Setting property", "property", fname, "value", val)
+			cv, ok := convertTo(val, fv.Type())
+			if !ok {
+				l.Warn("Invalid value", "property", fname, "value", val)
 				continue
 			}
-			v.Elem().Field(i).Set(reflect.ValueOf(val))
+			fv.Set(cv)
 		}
 	}
 }
+
+// This function parses the node storing a map field into the map of given type
+func parseMap(l Logger, n *protos.Node, t reflect.Type) reflect.Value {
+	m := reflect.MakeMap(t)
+	for k, val := range nodeMap(l, n) {
+		if k == "_xid_" || k == "_uid_" {
+			continue
+		}
+		ev, ok := convertTo(val, t.Elem())
+		if !ok {
+			l.Warn("Invalid map value", "key", k, "value", val)
+			continue
+		}
+		m.SetMapIndex(reflect.ValueOf(k).Convert(t.Key()), ev)
+	}
+	return m
+}
@@ -0,0 +1,381 @@
+package dgogm_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/akshaydeo/dgogm"
+	"github.com/dgraph-io/dgraph/protos"
+)
+
+func TestDgQuery_QueryWithFilter(t *testing.T) {
+	q, err := dgogm.Find(nil, &Dog1{}).Where(dgogm.Eq("Name", "jarvis")).Fields("name").Query()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if q != `{Dog1(func: eq(name, "jarvis")){_xid_ _uid_ name}}` {
+		t.Fatal(q)
+	}
+}
+
+func TestDgQuery_QueryWithAndFilter(t *testing.T) {
+	q, err := dgogm.Find(nil, &Dog1{}).Where(dgogm.AnyOfTerms("name", "jarvis friday"), dgogm.Not(dgogm.Has("color"))).Fields("name").Query()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if q != `{Dog1(func: anyofterms(name, "jarvis friday")) @filter((anyofterms(name, "jarvis friday") AND NOT has(color))){_xid_ _uid_ name}}` {
+		t.Fatal(q)
+	}
+}
+
+func TestDgQuery_QueryWithOrFilter(t *testing.T) {
+	q, err := dgogm.Find(nil, &Settings{}).Where(dgogm.Or(dgogm.Lt("retries", 2), dgogm.Regexp("uid", "^wh.*$"))).Fields("email").Query()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if q != `{Settings(func: has(email)) @filter((lt(retries, 2) OR regexp(uid, /^wh.*$/))){_xid_ _uid_ email}}` {
+		t.Fatal(q)
+	}
+	// Nodes missing the root predicate would be left out without a required field
+	_, err = dgogm.Find(nil, &Dog1{}).Where(dgogm.Or(dgogm.Lt("uid", 2), dgogm.Regexp("Color", "^wh.*$"))).Query()
+	if err == nil {
+		t.Fatal("Or at the root should fail without a required field")
+	}
+}
+
+type Email string
+
+func TestDgQuery_QueryWithFilterLiterals(t *testing.T) {
+	email := "a@b.com"
+	age := 3
+	tests := []struct {
+		value    interface{}
+		expected string
+	}{
+		{"a@b.com", `"a@b.com"`},
+		{Email("a@b.com"), `"a@b.com"`},
+		{&email, `"a@b.com"`},
+		{dgogm.NullOf("a@b.com"), `"a@b.com"`},
+		{dgogm.NullOf(Email("a@b.com")), `"a@b.com"`},
+		{"", `""`},
+		{3, "3"},
+		{&age, "3"},
+		{uint8(3), "3"},
+		{1.5, "1.5"},
+		{true, "true"},
+		{time.Date(2017, 8, 1, 10, 0, 0, 0, time.UTC), `"2017-08-01T10:00:00Z"`},
+	}
+	for _, test := range tests {
+		q, err := dgogm.Find(nil, &Dog1{}).Where(dgogm.Eq("Name", test.value)).Fields("name").Query()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if q != `{Dog1(func: eq(name, `+test.expected+`)){_xid_ _uid_ name}}` {
+			t.Fatal(q)
+		}
+	}
+}
+
+func TestDgQuery_QueryWithInvalidFilter(t *testing.T) {
+	q, err := dgogm.Find(nil, &Settings{}).Where(dgogm.Not(dgogm.Regexp("email", "^a/b$"))).Query()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if q != `{Settings(func: has(email)) @filter(NOT regexp(email, /^a\/b$/)){_xid_ _uid_ uid enabled retries email views}}` {
+		t.Fatal(q)
+	}
+	_, err = dgogm.Find(nil, &Dog1{}).Where(dgogm.Or()).Query()
+	if err == nil {
+		t.Fatal("Query should fail for empty OR")
+	}
+	_, err = dgogm.Find(nil, &Dog1{}).Where(dgogm.Eq("name", "jarvis"), dgogm.And()).Query()
+	if err == nil {
+		t.Fatal("Query should fail for empty AND")
+	}
+}
+
+func TestDgQuery_QueryWithUnknownField(t *testing.T) {
+	_, err := dgogm.Find(nil, &Dog1{}).Where(dgogm.Eq("age", 2)).Query()
+	if err == nil {
+		t.Fail()
+	}
+}
+
+func TestDgQuery_QueryWithNestedFields(t *testing.T) {
+	q, err := dgogm.Find(nil, &Dog{}).Where(dgogm.Eq("name", "jarvis")).Fields("Name", "likes_places.name").Query()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if q != `{Dog(func: eq(name, "jarvis")){_xid_ _uid_ name likes_places { _xid_ _uid_ name }}}` {
+		t.Fatal(q)
+	}
+}
+
+func TestDgQuery_QueryForFindAll(t *testing.T) {
+	q, err := dgogm.FindAll(nil, &[]*Dog1{}).Where(dgogm.Has("name")).Fields("name").Query()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if q != `{Dog1(func: has(name)){_xid_ _uid_ name}}` {
+		t.Fatal(q)
+	}
+}
+
+func TestDgQuery_QueryForFindAllWithoutFilter(t *testing.T) {
+	_, err := dgogm.FindAll(nil, &[]Dog1{}).Query()
+	if err == nil {
+		t.Fail()
+	}
+}
+
+func TestDgQuery_QueryWithPagination(t *testing.T) {
+	q, err := dgogm.FindAll(nil, &[]Dog{}).Where(dgogm.Has("name")).Fields("name", "Likes").
+		First(10).Offset(5).OrderDesc("Name").OrderAsc("Likes.Name").First(2, "likes_places").Query()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if q != `{Dog(func: has(name), orderdesc: name, first: 10, offset: 5){_xid_ _uid_ name likes_places (orderasc: name, first: 2) { _xid_ _uid_ uid name }}}` {
+		t.Fatal(q)
+	}
+}
+
+func TestDgQuery_QueryByIdWithPagination(t *testing.T) {
+	q, err := dgogm.Find(nil, &Dog1{Id: 1}).Fields("name").First(1).Query()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if q != `{Dog1(func: uid(0x3d511cd3378a9a14), first: 1){_xid_ _uid_ name}}` {
+		t.Fatal(q)
+	}
+}
+
+func TestDgQuery_QueryWithCursorAndOrder(t *testing.T) {
+	_, err := dgogm.FindAll(nil, &[]Dog1{}).Where(dgogm.Has("name")).OrderAsc("name").After(0x1a).Query()
+	if err == nil {
+		t.Fatal("Query should fail for ordered cursor")
+	}
+}
+
+func TestDgQuery_QueryWithCursor(t *testing.T) {
+	q, err := dgogm.FindAll(nil, &[]Dog1{}).Where(dgogm.Has("name")).Fields("name").First(10).After(0x1a).Query()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if q != `{Dog1(func: has(name), first: 10, after: 0x1a){_xid_ _uid_ name}}` {
+		t.Fatal(q)
+	}
+	_, err = dgogm.FindAll(nil, &[]Dog1{}).Where(dgogm.Has("name")).AfterCursor("not a cursor").Query()
+	if err == nil {
+		t.Fail()
+	}
+}
+
+type Dog9 struct {
+	Id         int     `dgraph:"uid"`
+	Name       string  `dgraph:"name"`
+	Likes      []Place `dgraph:"likes_places"`
+	LikesCount int     `dgraph:"count(likes_places)"`
+}
+
+func TestDgQuery_QueryWithCountEdge(t *testing.T) {
+	q, err := dgogm.FindAll(nil, &[]Dog9{}).Where(dgogm.Has("name")).Fields("name").CountEdge("Likes").Query()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if q != `{Dog9(func: has(name)){_xid_ _uid_ name count(likes_places)}}` {
+		t.Fatal(q)
+	}
+}
+
+type Product struct {
+	Id     string            `dgraph:"uid"`
+	Name   string            `dgraph:"name"`
+	Attrs  map[string]string `dgraph:"attrs"`
+	Prices map[string]int    `dgraph:"prices,json"`
+}
+
+func TestDgQuery_QueryWithMapFields(t *testing.T) {
+	q, err := dgogm.Find(nil, &Product{}).Where(dgogm.Eq("name", "collar")).Fields("name", "attrs", "prices").Query()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if q != `{Product(func: eq(name, "collar")){_xid_ _uid_ name attrs { _xid_ _uid_ expand(_all_) } prices}}` {
+		t.Fatal(q)
+	}
+}
+
+type Audit struct {
+	CreatedAt time.Time `dgraph:"created_at"`
+	UpdatedBy string    `dgraph:"updated_by"`
+}
+
+type Meta struct {
+	Version int `dgraph:"version"`
+}
+
+type Listing struct {
+	Id string `dgraph:"uid"`
+	Audit
+	*Meta
+	Owner  Audit  `dgraph:"owner"`
+	Name   string `dgraph:"name"`
+	secret string
+}
+
+func TestDgQuery_QueryWithEmbeddedStructs(t *testing.T) {
+	q, err := dgogm.Find(nil, &Listing{}).Where(dgogm.Eq("UpdatedBy", "admin")).Query()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if q != `{Listing(func: eq(updated_by, "admin")){_xid_ _uid_ uid created_at updated_by version owner { _xid_ _uid_ created_at updated_by } name}}` {
+		t.Fatal(q)
+	}
+}
+
+func TestDgQuery_QueryWithTagOptions(t *testing.T) {
+	q, err := dgogm.Find(nil, &Settings{}).Where(dgogm.Eq("enabled", true)).Query()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if q != `{Settings(func: eq(enabled, true)){_xid_ _uid_ uid enabled retries email views}}` {
+		t.Fatal(q)
+	}
+}
+
+type Account struct {
+	Email string `dgraph:"email,key"`
+	Org   string `dgraph:"org,key"`
+	Name  string `dgraph:"name"`
+}
+
+type Member struct {
+	Uid  string `dgraph:"_uid_"`
+	Xid  string `dgraph:"_xid_"`
+	Name string `dgraph:"name"`
+}
+
+func TestDgQuery_QueryWithIdentityStrategies(t *testing.T) {
+	dgogm.RegisterIdentityStrategy(&Account{}, dgogm.CompositeKey)
+	dgogm.RegisterIdentityStrategy(&Member{}, dgogm.ServerAssigned)
+	defer dgogm.RegisterIdentityStrategy(&Account{}, nil)
+	defer dgogm.RegisterIdentityStrategy(&Member{}, nil)
+	q, err := dgogm.Find(nil, &Account{Email: "tony@stark.com", Org: "stark"}).Query()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if q != `{Account(func: uid(0x167a2c77b24cc82f)){_xid_ _uid_ email org name}}` {
+		t.Fatal(q)
+	}
+	_, err = dgogm.Find(nil, &Account{Email: "tony@stark.com"}).Query()
+	if err == nil {
+		t.Fatal("Query should fail without all the key fields")
+	}
+	// Keys containing the separator do not collide
+	q, err = dgogm.Find(nil, &Account{Email: "a_b", Org: "c"}).Query()
+	if err != nil {
+		t.Fatal(err)
+	}
+	other, err := dgogm.Find(nil, &Account{Email: "a", Org: "b_c"}).Query()
+	if err != nil || q == other {
+		t.Fatal(q)
+	}
+	q, err = dgogm.Find(nil, &Member{Uid: "0x1a"}).Query()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if q != `{Member(func: uid(0x1a)){_xid_ _uid_ name}}` {
+		t.Fatal(q)
+	}
+	q, err = dgogm.Find(nil, &Member{Xid: "tony"}).Query()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if q != `{Member(func: eq(_xid_, "tony")){_xid_ _uid_ name}}` {
+		t.Fatal(q)
+	}
+}
+
+type Pet struct {
+	Uid    string    `dgraph:"_uid_"`
+	Name   string    `dgraph:"name"`
+	Age    int       `dgraph:"age"`
+	Born   time.Time `dgraph:"born"`
+	Tags   []string  `dgraph:"tags"`
+	Weight *float64  `dgraph:"weight"`
+	Owner  *Audit    `dgraph:"owner"`
+}
+
+func TestParseNodeTo(t *testing.T) {
+	n := &protos.Node{
+		Attribute: "Pet",
+		Properties: []*protos.Property{
+			{Prop: "_uid_", Value: &protos.Value{Val: &protos.Value_UidVal{UidVal: 0x1a}}},
+			{Prop: "name", Value: &protos.Value{Val: &protos.Value_StrVal{StrVal: "jarvis"}}},
+			{Prop: "age", Value: &protos.Value{Val: &protos.Value_IntVal{IntVal: 3}}},
+			{Prop: "born", Value: &protos.Value{Val: &protos.Value_DatetimeVal{DatetimeVal: []byte("2017-08-01T10:00:00Z")}}},
+			{Prop: "tags", Value: &protos.Value{Val: &protos.Value_StrVal{StrVal: "good"}}},
+			{Prop: "tags", Value: &protos.Value{Val: &protos.Value_StrVal{StrVal: "boy"}}},
+			{Prop: "weight", Value: &protos.Value{Val: &protos.Value_DoubleVal{DoubleVal: 12.5}}},
+		},
+		Children: []*protos.Node{{
+			Attribute: "owner",
+			Properties: []*protos.Property{
+				{Prop: "updated_by", Value: &protos.Value{Val: &protos.Value_StrVal{StrVal: "tony"}}},
+			},
+		}},
+	}
+	p := new(Pet)
+	dgogm.ParseNodeTo(n, p)
+	if p.Uid != "0x1a" || p.Name != "jarvis" || p.Age != 3 || !p.Born.Equal(time.Date(2017, 8, 1, 10, 0, 0, 0, time.UTC)) {
+		t.Fatal(p)
+	}
+	if len(p.Tags) != 2 || p.Tags[1] != "boy" || p.Weight == nil || *p.Weight != 12.5 {
+		t.Fatal(p)
+	}
+	if p.Owner == nil || p.Owner.UpdatedBy != "tony" {
+		t.Fatal(p.Owner)
+	}
+}
+
+type Crate struct {
+	Id   int    `dgraph:"uid"`
+	Xid  string `dgraph:"_xid_"`
+	Name string `dgraph:"name"`
+}
+
+func TestDgQuery_QueryByXid(t *testing.T) {
+	// _xid_ written back after adding finds the same node
+	q, err := dgogm.Find(nil, &Toy{Xid: "ball_toy"}).Query()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if q != "{Toy(func: uid(0x84d77b00a65e3bfb)){_xid_ _uid_ name}}" {
+		t.Fatal(q)
+	}
+	// The uid field takes precedence over _xid_
+	q, err = dgogm.Find(nil, &Crate{Id: 7, Xid: "8_crate"}).Query()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if q != "{Crate(func: uid(0xbc4589fc418ed67a)){_xid_ _uid_ uid name}}" {
+		t.Fatal(q)
+	}
+	other, err := dgogm.Find(nil, &Crate{Id: 7}).Query()
+	if err != nil || q != other {
+		t.Fatal(other)
+	}
+}
+
+func TestParseNodeToWithLegacyJsonArray(t *testing.T) {
+	n := &protos.Node{
+		Attribute: "Pet",
+		Properties: []*protos.Property{
+			{Prop: "tags", Value: &protos.Value{Val: &protos.Value_StrVal{StrVal: `["good","boy"]`}}},
+		},
+	}
+	p := new(Pet)
+	dgogm.ParseNodeTo(n, p)
+	if len(p.Tags) != 2 || p.Tags[0] != "good" || p.Tags[1] != "boy" {
+		t.Fatal(p.Tags)
+	}
+}
@@ -0,0 +1,238 @@
+package dgogm
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/dgraph-io/dgraph/client"
+)
+
+// This struct defines schema of a single predicate
+type schemaPredicate struct {
+	name    string
+	typ     string
+	index   []string
+	reverse bool
+	count   bool
+}
+
+// This function converts the predicate to dgraph schema line
+func (sp *schemaPredicate) String() string {
+	str := fmt.Sprintf("%s: %s", sp.name, sp.typ)
+	if len(sp.index) != 0 {
+		str = fmt.Sprintf("%s @index(%s)", str, strings.Join(sp.index, ", "))
+	}
+	if sp.reverse {
+		str = fmt.Sprintf("%s @reverse", str)
+	}
+	if sp.count {
+		str = fmt.Sprintf("%s @count", str)
+	}
+	return fmt.Sprintf("%s .", str)
+}
+
+// This function returns the dgraph schema for the given structs
+// Types of the predicates are inferred from the go types, structs and slices of structs are uid edges
+// Indexes and directives are given as tag options i.e. `dgraph:"name,index=term|exact,reverse,count"`
+// @upsert is not generated as dgraph 0.8 does not support it, Upsert looks the nodes up without it
+// If a predicate is shared by multiple structs, the first definition is used
+// Conflicting definitions are logged, Migrate and PlanMigration fail with ErrSchemaConflict for them
+func Schema(types ...interface{}) string {
+	predicates, err := getSchema(types...)
+	if err != nil {
+		getLogger().Warn("Conflicting schema, using the first definition", "error", err)
+	}
+	return schemaString(predicates)
+}
+
+// This function converts the predicates to dgraph schema
+func schemaString(predicates []*schemaPredicate) string {
+	lines := []string{}
+	for _, sp := range predicates {
+		lines = append(lines, sp.String())
+	}
+	return strings.Join(lines, "\n")
+}
+
+// This function applies the schema of the given structs to the dgraph
+func (d *Dgraph) Migrate(types ...interface{}) error {
+	return d.MigrateContext(context.Background(), types...)
+}
+
+// This function applies the schema of the given structs to the dgraph
+// The mutation is cancelled when the given context is done
+func (d *Dgraph) MigrateContext(ctx context.Context, types ...interface{}) error {
+	return migrate(ctx, d.log(), d.client, types...)
+}
+
+// This function applies the schema of the given structs to the dgraph
+func Migrate(c *client.Dgraph, types ...interface{}) error {
+	return MigrateContext(context.Background(), c, types...)
+}
+
+// This function applies the schema of the given structs to the dgraph
+// The mutation is cancelled when the given context is done
+func MigrateContext(ctx context.Context, c *client.Dgraph, types ...interface{}) error {
+	return migrate(ctx, getLogger(), c, types...)
+}
+
+// Internal function, altering the schema for the given structs
+func migrate(ctx context.Context, l Logger, c *client.Dgraph, types ...interface{}) error {
+	predicates, err := getSchema(types...)
+	if err != nil {
+		return err
+	}
+	return alterSchema(ctx, l, c, schemaString(predicates))
+}
+
+// Internal function, altering the schema
+func alterSchema(ctx context.Context, l Logger, c *client.Dgraph, schema string) error {
+	l.Info("Altering schema", "schema", schema)
+	r := new(client.Req)
+	r.SetQuery(fmt.Sprintf(ALTER_SCHEMA, schema))
+	_, err := runRequest(c, ctx, r)
+	if err != nil {
+		l.Error("Schema mutation failed", "error", err)
+		return &MutationError{Xid: "schema", Cause: err}
+	}
+	return nil
+}
+
+// This function walks the given structs and returns the predicates in the order they are found
+// ErrSchemaConflict is returned along with the predicates if a predicate is defined differently by the structs
+func getSchema(types ...interface{}) ([]*schemaPredicate, error) {
+	predicates := []*schemaPredicate{{name: "_xid_", typ: "string", index: []string{"exact"}}}
+	seen := map[string]*schemaPredicate{"_xid_": predicates[0]}
+	visited := map[reflect.Type]bool{}
+	var conflict error
+	for _, i := range types {
+		t := reflect.TypeOf(i)
+		for t != nil && t.Kind() == reflect.Ptr {
+			t = t.Elem()
+		}
+		if t == nil || t.Kind() != reflect.Struct {
+			continue
+		}
+		err := getSchemaFor(t, &predicates, seen, visited)
+		if err != nil && conflict == nil {
+			conflict = err
+		}
+	}
+	return predicates, conflict
+}
+
+// This function recursively adds predicates of the given struct type
+// Predicates which are already seen are kept, the first conflicting definition is returned as ErrSchemaConflict
+func getSchemaFor(t reflect.Type, predicates *[]*schemaPredicate, seen map[string]*schemaPredicate, visited map[reflect.Type]bool) error {
+	if visited[t] {
+		return nil
+	}
+	visited[t] = true
+	var conflict error
+	for _, f := range nodeFields(t) {
+		name := getFieldName(f)
+		if name == "-" || isCountField(name) || name == "_uid_" || name == "_xid_" {
+			continue
+		}
+		opts := getFieldOptions(f)
+		typ := schemaType(f.Type)
+		if _, ok := opts["json"]; ok && (f.Type.Kind() == reflect.Slice || f.Type.Kind() == reflect.Map) {
+			// Legacy encoding of slices and maps as json string
+			typ = "string"
+		}
+		if _, ok := opts["string"]; ok && (typ == "int" || typ == "float" || typ == "bool") {
+			typ = "string"
+		}
+		if typ == "" {
+			continue
+		}
+		sp := &schemaPredicate{name: name, typ: typ}
+		if index, ok := opts["index"]; ok && index != "" {
+			sp.index = strings.Split(index, "|")
+		}
+		_, sp.reverse = opts["reverse"]
+		_, sp.count = opts["count"]
+		if prev, ok := seen[name]; !ok {
+			seen[name] = sp
+			*predicates = append(*predicates, sp)
+		} else if conflicts(prev, sp) && conflict == nil {
+			conflict = ErrSchemaConflict{Predicate: name, First: prev.String(), Second: sp.String()}
+		}
+		// Maps are stored as nodes with a predicate per key, which can not be known upfront
+		if nt, ok := nodeType(f.Type); ok && typ == "uid" {
+			err := getSchemaFor(nt, predicates, seen, visited)
+			if err != nil && conflict == nil {
+				conflict = err
+			}
+		}
+	}
+	return conflict
+}
+
+// This function returns if the given definitions of the same predicate can not be applied together
+// Definitions conflict if they differ in type or both of them define different indexes
+func conflicts(a, b *schemaPredicate) bool {
+	if a.typ != b.typ {
+		return true
+	}
+	if len(a.index) == 0 || len(b.index) == 0 {
+		return false
+	}
+	return strings.Join(a.index, "|") != strings.Join(b.index, "|")
+}
+
+// This function returns the dgraph schema type for the given go type
+// Empty string is returned for the types which can not be stored
+func schemaType(t reflect.Type) string {
+	switch t {
+	case timeType:
+		return "datetime"
+	case geoPointType:
+		return "geo"
+	case bytesType, rawMessageType:
+		return "string"
+	}
+	if nt, ok := nullValueType(t); ok {
+		return schemaType(nt)
+	}
+	// Custom types are stored as string unless they are of a primitive kind i.e. type Status int
+	if t.Kind() != reflect.Ptr && (isMarshalerType(t) || implements(t, valueUnmarshalerType)) {
+		if implements(t, textMarshalerType) && !implements(t, valueMarshalerType) {
+			return "string"
+		}
+		switch t.Kind() {
+		case reflect.Struct, reflect.Array, reflect.Slice, reflect.Map:
+			return "string"
+		}
+	}
+	switch t.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return "int"
+	case reflect.Float32, reflect.Float64:
+		return "float"
+	case reflect.String:
+		return "string"
+	case reflect.Bool:
+		return "bool"
+	case reflect.Struct:
+		return "uid"
+	case reflect.Ptr:
+		return schemaType(t.Elem())
+	case reflect.Slice:
+		if isPrimitiveType(t.Elem()) {
+			// Slices of primitive types are stored as list predicates
+			return fmt.Sprintf("[%s]", schemaType(t.Elem()))
+		}
+		if _, ok := nodeType(t.Elem()); ok {
+			return "uid"
+		}
+	case reflect.Map:
+		if isMapType(t) {
+			return "uid"
+		}
+	}
+	return ""
+}
@@ -0,0 +1,213 @@
+package dgogm_test
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/akshaydeo/dgogm"
+	"github.com/dgraph-io/dgraph/protos"
+)
+
+type Owner struct {
+	Id        string    `dgraph:"uid"`
+	Email     string    `dgraph:"email,index=exact"`
+	Name      string    `dgraph:"name,index=term|exact"`
+	Age       int       `dgraph:"age"`
+	Rating    float64   `dgraph:"rating"`
+	Verified  bool      `dgraph:"verified"`
+	Joined    time.Time `dgraph:"joined"`
+	Dogs      []*Dog1   `dgraph:"owns,reverse,count"`
+	DogsCount int       `dgraph:"count(owns)"`
+	Secret    string    `dgraph:"-"`
+	Nicknames []string  `dgraph:"nicknames,index=term"`
+	Scores    []*int    `dgraph:"scores"`
+	Tags      []string  `dgraph:"tags,json"`
+}
+
+func TestSchema(t *testing.T) {
+	schema := dgogm.Schema(&Owner{})
+	expected := `_xid_: string @index(exact) .
+uid: string .
+email: string @index(exact) .
+name: string @index(term, exact) .
+age: int .
+rating: float .
+verified: bool .
+joined: datetime .
+owns: uid @reverse @count .
+color: string .
+nicknames: [string] @index(term) .
+scores: [int] .
+tags: string .`
+	if schema != expected {
+		t.Fatal(schema)
+	}
+}
+
+type Kennel struct {
+	Id       string          `dgraph:"uid"`
+	Opened   *time.Time      `dgraph:"opened"`
+	Location dgogm.GeoPoint  `dgraph:"location,index=geo"`
+	Logo     []byte          `dgraph:"logo"`
+	Metadata json.RawMessage `dgraph:"metadata"`
+}
+
+func TestSchemaScalars(t *testing.T) {
+	schema := dgogm.Schema(&Kennel{})
+	expected := `_xid_: string @index(exact) .
+uid: string .
+opened: datetime .
+location: geo @index(geo) .
+logo: string .
+metadata: string .`
+	if schema != expected {
+		t.Fatal(schema)
+	}
+}
+
+type Status int
+
+func (s Status) MarshalDgraph() (interface{}, error) {
+	return int(s), nil
+}
+
+func (s *Status) UnmarshalDgraph(val interface{}) error {
+	i, ok := val.(int64)
+	if !ok {
+		return fmt.Errorf("invalid status %v", val)
+	}
+	*s = Status(i)
+	return nil
+}
+
+type Amount struct {
+	Cents int64
+}
+
+func (a Amount) MarshalText() ([]byte, error) {
+	return []byte(strconv.FormatInt(a.Cents, 10)), nil
+}
+
+func (a *Amount) UnmarshalText(text []byte) error {
+	c, err := strconv.ParseInt(string(text), 10, 64)
+	a.Cents = c
+	return err
+}
+
+type Invoice struct {
+	Id       string   `dgraph:"uid"`
+	Status   Status   `dgraph:"status,index=int"`
+	Total    Amount   `dgraph:"total"`
+	Discount *Amount  `dgraph:"discount"`
+	Lines    []Amount `dgraph:"lines"`
+}
+
+func TestSchemaCustomTypes(t *testing.T) {
+	schema := dgogm.Schema(&Invoice{})
+	expected := `_xid_: string @index(exact) .
+uid: string .
+status: int @index(int) .
+total: string .
+discount: string .
+lines: [string] .`
+	if schema != expected {
+		t.Fatal(schema)
+	}
+}
+
+func TestSchemaMaps(t *testing.T) {
+	schema := dgogm.Schema(&Product{})
+	expected := `_xid_: string @index(exact) .
+uid: string .
+name: string .
+attrs: uid .
+prices: string .`
+	if schema != expected {
+		t.Fatal(schema)
+	}
+}
+
+func TestSchemaEmbeddedStructs(t *testing.T) {
+	schema := dgogm.Schema(&Listing{})
+	expected := `_xid_: string @index(exact) .
+uid: string .
+created_at: datetime .
+updated_by: string .
+version: int .
+owner: uid .
+name: string .`
+	if schema != expected {
+		t.Fatal(schema)
+	}
+}
+
+type Settings struct {
+	Id      string `dgraph:"uid"`
+	Enabled bool   `json:"enabled,omitempty"`
+	Retries int    `dgraph:"retries,string"`
+	Email   string `dgraph:"email,required"`
+	Views   int    `dgraph:"views,readonly"`
+	Cache   string `json:"-"`
+}
+
+func TestSchemaTagOptions(t *testing.T) {
+	schema := dgogm.Schema(&Settings{})
+	expected := `_xid_: string @index(exact) .
+uid: string .
+enabled: bool .
+retries: string .
+email: string .
+views: int .`
+	if schema != expected {
+		t.Fatal(schema)
+	}
+}
+
+func TestDiffSchema(t *testing.T) {
+	live := []*protos.SchemaNode{
+		{Predicate: "_xid_", Type: "string", Index: true, Tokenizer: []string{"exact"}},
+		{Predicate: "uid", Type: "default"},
+		{Predicate: "opened", Type: "string"},
+		{Predicate: "location", Type: "geo", Index: true, Tokenizer: []string{"geo"}},
+		{Predicate: "metadata", Type: "string", Index: true, Tokenizer: []string{"term"}},
+	}
+	plan, err := dgogm.DiffSchema(live, &Kennel{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := `change_type uid: default => string
+change_type opened: string => datetime (destructive)
+add_predicate logo:  => logo: string .
+remove_index metadata: term =>  (destructive)`
+	if plan.String() != expected {
+		t.Fatal(plan.String())
+	}
+	// Typing the default predicate is kept, predicates with destructive steps are dropped
+	plan = plan.WithoutDestructive()
+	if plan.Schema() != "logo: string .\nuid: string ." {
+		t.Fatal(plan.Schema())
+	}
+}
+
+type Litter struct {
+	Name string `dgraph:"name"`
+	Size int    `dgraph:"size,index=int"`
+}
+
+type Show struct {
+	Name string `dgraph:"name,index=term"`
+	Size string `dgraph:"size"`
+}
+
+func TestSchemaConflicts(t *testing.T) {
+	// Index of name defined by only one of the structs is not a conflict, but types of size differ
+	_, err := dgogm.DiffSchema(nil, &Litter{}, &Show{})
+	conflict := dgogm.ErrSchemaConflict{}
+	if !errors.As(err, &conflict) || conflict.Predicate != "size" {
+		t.Fatal(err)
+	}
+}
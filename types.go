@@ -1,9 +1,28 @@
 package dgogm
 
-import "reflect"
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+)
+
+var (
+	timeType       = reflect.TypeOf(time.Time{})
+	bytesType      = reflect.TypeOf([]byte{})
+	rawMessageType = reflect.TypeOf(json.RawMessage{})
+	geoPointType   = reflect.TypeOf(GeoPoint{})
+)
 
 // This function returns if given type is a or points to a primitive type
+// Scalar types like time.Time are considered primitive as they are stored as a single predicate
 func isPrimitiveType(tp reflect.Type) bool {
+	if isScalarType(tp) {
+		return true
+	}
 	switch tp.Kind() {
 	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
 		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
@@ -14,3 +33,237 @@ func isPrimitiveType(tp reflect.Type) bool {
 	}
 	return false
 }
+
+// This function returns if given type is a map which can be stored as a node, keys being the predicates
+// i.e. map[string]string or map[string]int
+func isMapType(tp reflect.Type) bool {
+	return tp.Kind() == reflect.Map && tp.Key().Kind() == reflect.String && isPrimitiveType(tp.Elem())
+}
+
+// This function returns the _xid_ of the node storing the map field of the node with the given uid
+func mapXid(uid uint64, fname string) string {
+	return fmt.Sprintf("%d_%s", uid, fname)
+}
+
+// This function returns if the given string can be used as a predicate i.e. the keys of the maps stored as nodes
+// Letters, digits, underscores, dots and hyphens are allowed, _uid_ and _xid_ are reserved
+func isPredicateName(name string) bool {
+	if name == "" || name == "_uid_" || name == "_xid_" {
+		return false
+	}
+	for _, r := range name {
+		if !unicode.IsLetter(r) && !unicode.IsDigit(r) && r != '_' && r != '.' && r != '-' {
+			return false
+		}
+	}
+	return true
+}
+
+// This function returns the fields of the given struct type which are stored as predicates of the node
+// Like go, fields of the embedded structs are promoted to the parent, Index of such fields is the path from the parent
+// Embedded structs named using tag i.e. `dgraph:"audit"` are kept as an edge, unexported fields are skipped
+func nodeFields(t reflect.Type) []reflect.StructField {
+	var fields []reflect.StructField
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if isEmbeddedStruct(f) {
+			et := f.Type
+			if et.Kind() == reflect.Ptr {
+				et = et.Elem()
+			}
+			for _, ef := range nodeFields(et) {
+				ef.Index = append([]int{i}, ef.Index...)
+				fields = append(fields, ef)
+			}
+			continue
+		}
+		if f.PkgPath != "" || getFieldName(f) == "-" {
+			continue
+		}
+		fields = append(fields, f)
+	}
+	return fields
+}
+
+// This function returns if the given field is an embedded struct whose fields are promoted to the parent
+func isEmbeddedStruct(f reflect.StructField) bool {
+	if !f.Anonymous || isScalarType(f.Type) {
+		return false
+	}
+	if name, _ := parseTag(f); name != "" {
+		return false
+	}
+	switch f.Type.Kind() {
+	case reflect.Struct:
+		return true
+	case reflect.Ptr:
+		// Unexported embedded pointers can not be initialized while loading
+		return f.Type.Elem().Kind() == reflect.Struct && f.PkgPath == ""
+	}
+	return false
+}
+
+// This function returns the field of the given struct value at the given index path
+// Nil embedded pointers on the way are initialized if alloc is true, otherwise false is returned
+func fieldValue(v reflect.Value, index []int, alloc bool) (reflect.Value, bool) {
+	for i, x := range index {
+		if i > 0 && v.Kind() == reflect.Ptr {
+			if v.IsNil() {
+				if !alloc || !v.CanSet() {
+					return reflect.Value{}, false
+				}
+				v.Set(reflect.New(v.Type().Elem()))
+			}
+			v = v.Elem()
+		}
+		v = v.Field(x)
+	}
+	return v, true
+}
+
+// This function returns if given value is a non nil pointer to struct
+func isStructPtr(p interface{}) bool {
+	v := reflect.ValueOf(p)
+	return v.Kind() == reflect.Ptr && !v.IsNil() && v.Elem().Kind() == reflect.Struct
+}
+
+// This function returns if given type is a or points to a type which is stored as a single predicate
+// although it's not of a primitive kind i.e. time.Time, []byte, json.RawMessage, GeoPoint and the types
+// implementing ValueMarshaler, ValueUnmarshaler or encoding.TextMarshaler
+func isScalarType(tp reflect.Type) bool {
+	switch tp {
+	case timeType, bytesType, rawMessageType, geoPointType:
+		return true
+	}
+	if isMarshalerType(tp) || implements(tp, valueUnmarshalerType) {
+		return true
+	}
+	if tp.Kind() == reflect.Ptr {
+		return isScalarType(tp.Elem())
+	}
+	return false
+}
+
+// This function converts the value returned by dgraph to the given type
+// Numbers are converted across kinds as dgraph returns int64 and float64 for all of them
+func convertTo(val interface{}, t reflect.Type) (reflect.Value, bool) {
+	if t.Kind() == reflect.Ptr {
+		v, ok := convertTo(val, t.Elem())
+		if !ok {
+			return reflect.Value{}, false
+		}
+		ptr := reflect.New(t.Elem())
+		ptr.Elem().Set(v)
+		return ptr, true
+	}
+	v := reflect.ValueOf(val)
+	if !v.IsValid() {
+		return reflect.Value{}, false
+	}
+	if v.Type() == t {
+		return v, true
+	}
+	// Custom unmarshalling takes precedence over the conversions
+	if implements(t, valueUnmarshalerType) || implements(t, textUnmarshalerType) {
+		if u, ok := unmarshalValue(val, t); ok {
+			return u, true
+		}
+	}
+	if isNumber(v.Kind()) && isNumber(t.Kind()) {
+		return v.Convert(t), true
+	}
+	// Named types i.e. type Color string
+	if v.Kind() == t.Kind() && v.Type().ConvertibleTo(t) {
+		return v.Convert(t), true
+	}
+	// json.RawMessage is stored as string
+	if v.Kind() == reflect.String && t.Kind() == reflect.Slice && t.Elem().Kind() == reflect.Uint8 {
+		return v.Convert(t), true
+	}
+	return reflect.Value{}, false
+}
+
+// This function returns if given kind is a number
+func isNumber(k reflect.Kind) bool {
+	switch k {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float64, reflect.Float32:
+		return true
+	}
+	return false
+}
+
+// This function returns if zero value of the given kind can be stored in dgraph i.e. false and 0
+// Zero values of other kinds are empty, and are never written
+func isZeroWritable(k reflect.Kind) bool {
+	return isNumber(k) || k == reflect.Bool
+}
+
+// This function parses the value stored as string for the fields with string option i.e. dgraph:"age,string"
+func parseString(val interface{}, t reflect.Type) (reflect.Value, bool) {
+	s, ok := val.(string)
+	if !ok {
+		return convertTo(val, t)
+	}
+	if t.Kind() == reflect.Ptr {
+		v, ok := parseString(val, t.Elem())
+		if !ok {
+			return reflect.Value{}, false
+		}
+		ptr := reflect.New(t.Elem())
+		ptr.Elem().Set(v)
+		return ptr, true
+	}
+	v := reflect.New(t).Elem()
+	switch t.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		i, err := strconv.ParseInt(s, 10, t.Bits())
+		if err != nil {
+			return reflect.Value{}, false
+		}
+		v.SetInt(i)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		u, err := strconv.ParseUint(s, 10, t.Bits())
+		if err != nil {
+			return reflect.Value{}, false
+		}
+		v.SetUint(u)
+	case reflect.Float32, reflect.Float64:
+		f, err := strconv.ParseFloat(s, t.Bits())
+		if err != nil {
+			return reflect.Value{}, false
+		}
+		v.SetFloat(f)
+	case reflect.Bool:
+		b, err := strconv.ParseBool(s)
+		if err != nil {
+			return reflect.Value{}, false
+		}
+		v.SetBool(b)
+	default:
+		return convertTo(val, t)
+	}
+	return v, true
+}
+
+// This function formats the value for the fields with string option i.e. dgraph:"age,string"
+func formatString(v reflect.Value) (string, bool) {
+	switch v.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return strconv.FormatInt(v.Int(), 10), true
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return strconv.FormatUint(v.Uint(), 10), true
+	case reflect.Float32, reflect.Float64:
+		return strconv.FormatFloat(v.Float(), 'g', -1, v.Type().Bits()), true
+	case reflect.Bool:
+		return strconv.FormatBool(v.Bool()), true
+	}
+	return "", false
+}
+
+// This function returns if given field name is a count of an edge i.e. count(likes_places)
+// Such fields are only loaded from dgraph and never written
+func isCountField(name string) bool {
+	return strings.HasPrefix(name, "count(") && strings.HasSuffix(name, ")")
+}
@@ -0,0 +1,126 @@
+package dgogm
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+
+	"github.com/dgraph-io/dgraph/client"
+	"github.com/dgraph-io/dgraph/protos"
+)
+
+// This function adds the given pointer to struct into the Dgraph, reusing the node having the same values of the key fields
+// Key fields can be the go field names or dgraph names, and should be indexed with exact or hash i.e. dgraph:"email,index=exact"
+// A new node is created as Add does if there is no such node, ErrMultipleNodes is returned if there are many
+// Lookup and the mutation are separate requests, so concurrent upserts of the same keys can create duplicates
+func (d *Dgraph) Upsert(p interface{}, keyFields ...string) error {
+	return d.UpsertContext(context.Background(), p, keyFields...)
+}
+
+// This function adds the given pointer to struct into the Dgraph reusing the existing node, same as Upsert
+// The lookup and the mutation are cancelled when the given context is done
+func (d *Dgraph) UpsertContext(ctx context.Context, p interface{}, keyFields ...string) error {
+	return upsertContext(ctx, d.log(), d.client, d.ids, p, keyFields)
+}
+
+// This function adds the given pointer to struct into the Dgraph, reusing the node having the same values of the key fields
+// Key fields can be the go field names or dgraph names, and should be indexed with exact or hash i.e. dgraph:"email,index=exact"
+// A new node is created as Add does if there is no such node, ErrMultipleNodes is returned if there are many
+// Lookup and the mutation are separate requests, so concurrent upserts of the same keys can create duplicates
+func Upsert(c *client.Dgraph, p interface{}, keyFields ...string) error {
+	return UpsertContext(context.Background(), c, p, keyFields...)
+}
+
+// This function adds the given pointer to struct into the Dgraph reusing the existing node, same as Upsert
+// The lookup and the mutation are cancelled when the given context is done
+func UpsertContext(ctx context.Context, c *client.Dgraph, p interface{}, keyFields ...string) error {
+	return upsertContext(ctx, getLogger(), c, nil, p, keyFields)
+}
+
+// Internal function, looking up the node by the key fields and adding the object into it with a single mutation
+// Dgraph does not support transactions, so the lookup and the mutation are separate requests
+// and concurrent upserts of the same keys can still create duplicate nodes
+func upsertContext(ctx context.Context, l Logger, c *client.Dgraph, ids IdentityStrategy, p interface{}, keyFields []string) error {
+	q, err := upsertQuery(c, l, p, keyFields)
+	if err != nil {
+		return err
+	}
+	nodes, err := query(ctx, l, c, q)
+	if err != nil {
+		return err
+	}
+	r, w, sid, err := upsertRequest(l, c, ids, p, nodes, keyFields)
+	if err != nil {
+		return err
+	}
+	return run(ctx, l, c, r, sid, w)
+}
+
+// This function returns the query looking up the nodes having the values of the key fields of p
+// Two nodes are fetched, so that multiple matches can be detected
+func upsertQuery(c *client.Dgraph, l Logger, p interface{}, keyFields []string) (string, error) {
+	if !isStructPtr(p) {
+		return "", ErrInvalidTarget
+	}
+	if len(keyFields) == 0 {
+		return "", errors.New("Upsert requires key fields")
+	}
+	filters, err := keyFilters(p, keyFields)
+	if err != nil {
+		return "", err
+	}
+	return (&DgQuery{client: c, s: p, logger: l}).Where(filters...).Fields(keyFields...).First(2).Query()
+}
+
+// This function builds the request adding the object into the node found by the lookup, or into a new node
+// ErrMultipleNodes is returned if more than one node has the values of the key fields
+func upsertRequest(l Logger, c *client.Dgraph, ids IdentityStrategy, p interface{}, nodes []*protos.Node, keyFields []string) (*client.Req, *write, string, error) {
+	w := newWrite(modeAdd, ids)
+	snode, sid, err := w.node(c, p)
+	if err != nil {
+		return nil, nil, "", err
+	}
+	if len(nodes) != 0 && len(nodes[0].Children) != 0 {
+		if len(nodes[0].Children) > 1 {
+			l.Error("Multiple nodes found for the keys", "keys", keyFields)
+			return nil, nil, "", ErrMultipleNodes
+		}
+		n := nodes[0].Children[0]
+		root := w.nodes[0]
+		if uid, ok := nodeUid(n); ok {
+			snode = c.NodeUid(uid)
+			root.uid, root.blank = uid, ""
+		}
+		// Keeping the _xid_ of the existing node
+		if xid, ok := nodeMap(l, n)["_xid_"].(string); ok && xid != "" {
+			sid = xid
+			root.xid = xid
+		}
+		l.Debug("Upserting into existing node", "xid", sid, "uid", root.uid)
+	}
+	r := new(client.Req)
+	_, err = add(l, c, r, w, snode, sid, p)
+	if err != nil {
+		return nil, nil, "", err
+	}
+	return r, w, sid, nil
+}
+
+// This function returns the equality filters for the values of the given key fields of p
+func keyFilters(p interface{}, keyFields []string) ([]Filter, error) {
+	t := reflect.TypeOf(p).Elem()
+	filters := make([]Filter, 0, len(keyFields))
+	for _, name := range keyFields {
+		f, ok := lookupField(t, name)
+		if !ok {
+			return nil, fmt.Errorf("%s does not have field %s", t.Name(), name)
+		}
+		fv, ok := fieldValue(reflect.ValueOf(p).Elem(), f.Index, false)
+		if !ok || IsZero(fv) {
+			return nil, ErrRequired{Field: f.Name}
+		}
+		filters = append(filters, Eq(getFieldName(f), reflect.Indirect(fv).Interface()))
+	}
+	return filters, nil
+}
@@ -13,7 +13,6 @@ import (
 
 	"github.com/dgraph-io/dgraph/client"
 	"github.com/dgraph-io/dgraph/protos"
-	"github.com/media-net/cargo/logger"
 	"github.com/satori/go.uuid"
 )
 
@@ -31,8 +30,19 @@ func IsZero(v reflect.Value) bool {
 		return v.Uint() == 0
 	case reflect.Float32, reflect.Float64:
 		return v.Float() == 0
-	case reflect.Interface, reflect.Map, reflect.Ptr, reflect.Slice:
+	case reflect.Interface, reflect.Ptr, reflect.Slice:
 		return v.IsNil()
+	case reflect.Map:
+		return v.Len() == 0
+	case reflect.Struct:
+		switch v.Type() {
+		case timeType:
+			return v.Interface().(time.Time).IsZero()
+		case geoPointType:
+			return v.Interface().(GeoPoint).Geometry.Type == ""
+		}
+		// Nested structs which are not given have no identity, so they are not written
+		return v.IsZero()
 	}
 	return false
 }
@@ -48,40 +58,93 @@ func convert(val *protos.Value) (interface{}, error) {
 		return val.GetIntVal(), nil
 	case *protos.Value_UidVal:
 		return val.GetUidVal(), nil
+	case *protos.Value_DoubleVal:
+		return val.GetDoubleVal(), nil
+	case *protos.Value_BytesVal:
+		return val.GetBytesVal(), nil
+	case *protos.Value_GeoVal:
+		return parseGeo(val.GetGeoVal())
 	case *protos.Value_DateVal:
-		logger.D("Parsing time")
-		t, err := time.Parse("2006-01-02 19:54:00.000000000 +0000 UTC", string(val.GetDatetimeVal()))
-		if err != nil {
-			logger.E("Error while parsing datetime", err)
-			return string(val.GetDatetimeVal()), err
-		}
-		return t, nil
+		return parseTime(string(val.GetDateVal()))
 	case *protos.Value_DatetimeVal:
-		logger.D("Parsing time")
-		t, err := time.Parse("2006-01-02 19:54:00.000000000 +0000 UTC", string(val.GetDatetimeVal()))
-		if err != nil {
-			logger.E("Error while parsing datetime", err)
-			return string(val.GetDatetimeVal()), err
-		}
-		return t, nil
+		return parseTime(string(val.GetDatetimeVal()))
 	default:
 		return val.GetDefaultVal(), nil
 	}
 	return nil, nil
 }
 
+// This function parses the datetime returned by dgraph
+// RFC3339 is expected, time.Time.String() format is supported for the values written by older versions
+func parseTime(val string) (interface{}, error) {
+	t, err := time.Parse(time.RFC3339Nano, val)
+	if err == nil {
+		return t, nil
+	}
+	t, lerr := time.Parse("2006-01-02 15:04:05.999999999 -0700 MST", val)
+	if lerr == nil {
+		return t, nil
+	}
+	return val, err
+}
+
+// This function parses the geojson returned by dgraph into GeoPoint
+// Dgraph returns only the geometry, which is wrapped into a feature
+func parseGeo(val []byte) (interface{}, error) {
+	gp := GeoPoint{}
+	err := json.Unmarshal(val, &gp)
+	if err != nil {
+		return string(val), err
+	}
+	if gp.Geometry.Type != "" {
+		return gp, nil
+	}
+	g := GeoGeometry{}
+	err = json.Unmarshal(val, &g)
+	if err != nil {
+		return string(val), err
+	}
+	return GeoPoint{Type: "Feature", Geometry: g}, nil
+}
+
 // This function returns the name for the given struct field
 // First dgraph -> then json -> then field name
 func getFieldName(f reflect.StructField) string {
+	name, _ := parseTag(f)
+	if name == "" {
+		return f.Name
+	}
+	return name
+}
+
+// This function returns the options given after the name in the dgraph tag, or json tag if there is no dgraph tag
+// i.e. dgraph:"name,index=term|exact,count" gives {"index": "term|exact", "count": ""}
+func getFieldOptions(f reflect.StructField) map[string]string {
+	_, opts := parseTag(f)
+	return opts
+}
+
+// This function parses the dgraph tag, or json tag if there is no dgraph tag, into name and options
+// Name is empty if the tag does not give one i.e. dgraph:",omitempty"
+func parseTag(f reflect.StructField) (string, map[string]string) {
+	opts := map[string]string{}
 	val, ok := f.Tag.Lookup("dgraph")
-	if ok {
-		return val
+	if !ok {
+		val, ok = f.Tag.Lookup("json")
 	}
-	val, ok = f.Tag.Lookup("json")
-	if ok {
-		return val
+	if !ok {
+		return "", opts
+	}
+	parts := strings.Split(val, ",")
+	for _, part := range parts[1:] {
+		kv := strings.SplitN(part, "=", 2)
+		if len(kv) == 2 {
+			opts[kv[0]] = kv[1]
+			continue
+		}
+		opts[kv[0]] = ""
 	}
-	return f.Name
+	return parts[0], opts
 }
 
 // This function returns a new 64-bit FNV-1a hash.Hash
@@ -95,6 +158,16 @@ func hash(i string) uint64 {
 // It checks if there is a function called UId which returns string,
 // if it's there, that function will be used to return the uid
 func GetUId(p interface{}) string {
+	if uid, ok := providedUId(p); ok {
+		return uid
+	}
+	// If there is no provided id, then generating random uuid
+	return uuid.NewV4().String()
+}
+
+// This function returns the uid provided by the UId function or the field named uid of p
+// It returns false if there is none, GetUId generates a random one then
+func providedUId(p interface{}) (string, bool) {
 	// Get type info of p
 	t := reflect.TypeOf(p)
 	if t.Kind() != reflect.Ptr {
@@ -105,33 +178,36 @@ func GetUId(p interface{}) string {
 		uid := reflect.ValueOf(p).MethodByName("UId").Call([]reflect.Value{})[0]
 		switch uid.Kind() {
 		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64, reflect.Bool, reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
-			return fmt.Sprintf("%d", uid.Int())
+			return fmt.Sprintf("%d", uid.Int()), true
 		case reflect.Float64, reflect.Float32:
-			return fmt.Sprintf("%f", uid.Float())
+			return fmt.Sprintf("%f", uid.Float()), true
 		case reflect.String:
-			return uid.String()
+			return uid.String(), true
 		}
 	}
-	for i := 0; i < t.Elem().NumField(); i++ {
-		if getFieldName(t.Elem().Field(i)) == "uid" {
-			switch t.Elem().Field(i).Type.Kind() {
+	for _, f := range nodeFields(t.Elem()) {
+		if getFieldName(f) == "uid" {
+			fv, ok := fieldValue(reflect.ValueOf(p).Elem(), f.Index, false)
+			if !ok {
+				continue
+			}
+			switch f.Type.Kind() {
 			case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64, reflect.Bool, reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
 				return fmt.Sprintf("%d_%s",
-					reflect.ValueOf(p).Elem().FieldByName(t.Elem().Field(i).Name).Int(),
-					strings.ToLower(t.Elem().Name()))
+					fv.Int(),
+					strings.ToLower(t.Elem().Name())), true
 			case reflect.Float64, reflect.Float32:
 				return fmt.Sprintf("%f_%s",
-					reflect.ValueOf(p).Elem().FieldByName(t.Elem().Field(i).Name).Float(),
-					strings.ToLower(t.Elem().Name()))
+					fv.Float(),
+					strings.ToLower(t.Elem().Name())), true
 			case reflect.String:
 				return fmt.Sprintf("%s_%s",
-					reflect.ValueOf(p).Elem().FieldByName(t.Elem().Field(i).Name).String(),
-					strings.ToLower(t.Elem().Name()))
+					fv.String(),
+					strings.ToLower(t.Elem().Name())), true
 			}
 		}
 	}
-	// If there is no provided id, then generating random uuid
-	return uuid.NewV4().String()
+	return "", false
 }
 
 // Get the corresponding protos.Value object for the given interface
@@ -147,7 +223,7 @@ func getVal(val interface{}) *protos.Value {
 	case float64:
 		return &protos.Value{Val: &protos.Value_DoubleVal{DoubleVal: val.(float64)}}
 	case time.Time:
-		return &protos.Value{Val: &protos.Value_DatetimeVal{DatetimeVal: []byte(val.(time.Time).String())}}
+		return &protos.Value{Val: &protos.Value_DatetimeVal{DatetimeVal: []byte(val.(time.Time).Format(time.RFC3339Nano))}}
 	case bool:
 		return &protos.Value{Val: &protos.Value_BoolVal{BoolVal: val.(bool)}}
 	case []byte:
@@ -163,6 +239,8 @@ func setVal(edge *client.Edge, val interface{}) error {
 	switch val.(type) {
 	case int, int64, int8, int32, int16:
 		return edge.SetValueInt(val.(int64))
+	case uint64:
+		return ErrOverflow{Value: val.(uint64)}
 	case string:
 		if val.(string) == "" {
 			return errors.New("Empty")
@@ -179,14 +257,14 @@ func setVal(edge *client.Edge, val interface{}) error {
 	case *GeoPoint:
 		return edge.SetValueGeoJson(*(val.(*GeoPoint).Json()))
 	}
-	return errors.New("Val type is not supported ")
+	return ErrUnsupportedType{Type: reflect.TypeOf(val)}
 }
 
 // This function gives pointer to json string of the struct, ignoring the errors
 func ToJsonUnsafe(v interface{}) string {
 	data, err := json.Marshal(v)
 	if err != nil {
-		Error("Error while converting to json", err)
+		getLogger().Error("Error while converting to json", "error", err)
 	}
 	return string(data)
 }
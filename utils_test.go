@@ -69,3 +69,19 @@ func TestGetUIdForUIdFunc(t *testing.T) {
 		t.Fail()
 	}
 }
+
+type Base struct {
+	Id string `dgraph:"uid"`
+}
+
+type Person4 struct {
+	Base
+	Name string `dgraph:"name"`
+}
+
+func TestGetUIdForEmbeddedId(t *testing.T) {
+	s := Person4{Base{"this_is_test"}, "Akshay Deo"}
+	if dgogm.GetUId(&s) != "this_is_test_person4" {
+		t.Fail()
+	}
+}